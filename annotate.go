@@ -0,0 +1,184 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+const (
+	// AnnotateErrorsHeader is the header of the column added by AnnotateErrors.
+	AnnotateErrorsHeader = "Errors"
+	// annotateErrorsColor is the fill color of the cells with errors, a light red.
+	annotateErrorsColor = "FFFFC7CE"
+)
+
+// AnnotateErrors writes a copy of the xlsx file to w, annotated with the errors of a ContentError,
+// as returned by reading `T` from that file with the same options.
+// Every cell of a FieldError is highlighted and gets a comment with the error texts,
+// and a column "Errors" is appended, which lists the errors of each row.
+// The file itself is not modified.
+func AnnotateErrors[T any](w io.Writer, f *xlsx.File, cerr ContentError, opts ...ReadOption) error {
+	rc, _, err := newReadConfig[T](opts...)
+	if err != nil {
+		return err
+	}
+	sidx, err := sheetIndex(xlsxWorkbook{file: f}.sheetNames(), rc)
+	if err != nil {
+		return err
+	}
+
+	// Copy the file by writing and parsing it again
+	buf := &bytes.Buffer{}
+	if err = f.Write(buf); err != nil {
+		return err
+	}
+	cp, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	sheet := cp.Sheets[sidx]
+
+	// Key: Row Index
+	// Value: Error texts of the row
+	rowErrors := make(map[int][]string)
+	var comments []cellComment
+	// Key: Row and column index
+	// Value: Index of the comment of the cell, as a cell has one comment
+	commentIndexes := make(map[[2]int]int)
+	highlighted := make(map[*xlsx.Style]*xlsx.Style)
+	for _, fer := range cerr.FieldErrors {
+		text := fmt.Sprintf("%s: %s", fer.ColumnHeader, errorText(fer.Err))
+		rowErrors[fer.RowIndex] = append(rowErrors[fer.RowIndex], text)
+		if fer.ColumnIndex < 0 {
+			continue
+		}
+		cell, err := sheet.Cell(fer.RowIndex, fer.ColumnIndex)
+		if err != nil {
+			return err
+		}
+		style := cell.GetStyle()
+		if highlighted[style] == nil {
+			h := *style
+			h.Fill = *xlsx.NewFill("solid", annotateErrorsColor, annotateErrorsColor)
+			h.ApplyFill = true
+			highlighted[style] = &h
+		}
+		cell.SetStyle(highlighted[style])
+		key := [2]int{fer.RowIndex, fer.ColumnIndex}
+		if i, ok := commentIndexes[key]; ok {
+			comments[i].text += "; " + errorText(fer.Err)
+			continue
+		}
+		commentIndexes[key] = len(comments)
+		comments = append(comments, cellComment{row: fer.RowIndex, col: fer.ColumnIndex, text: errorText(fer.Err)})
+	}
+	for _, re := range cerr.RowErrors {
+		rowErrors[re.RowIndex] = append(rowErrors[re.RowIndex], errorText(re.Err))
+	}
+
+	errorsColumn := sheet.MaxCol
+	if !rc.NoHeaderRow {
+		cell, err := sheet.Cell(rc.HeaderRowIndex, errorsColumn)
+		if err != nil {
+			return err
+		}
+		cell.SetString(AnnotateErrorsHeader)
+	}
+	for rowIndex, texts := range rowErrors {
+		cell, err := sheet.Cell(rowIndex, errorsColumn)
+		if err != nil {
+			return err
+		}
+		cell.SetString(strings.Join(texts, "; "))
+	}
+
+	p, err := marshalPackage(cp)
+	if err != nil {
+		return err
+	}
+	if err = p.addComments(sidx, comments); err != nil {
+		return err
+	}
+	return p.Write(w)
+}
+
+// errorText returns the text of the error without the wrapping FieldError or RowError.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// cellComment is a note attached to a cell.
+type cellComment struct {
+	row, col int
+	text     string
+}
+
+// addComments adds the comments to the sheet,
+// as comments part and the legacy VML drawing Excel needs to show them.
+func (p *xlsxPackage) addComments(sheetIndex int, comments []cellComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].row != comments[j].row {
+			return comments[i].row < comments[j].row
+		}
+		return comments[i].col < comments[j].col
+	})
+
+	commentsXML := &strings.Builder{}
+	vmlXML := &strings.Builder{}
+	commentsXML.WriteString(xml.Header)
+	commentsXML.WriteString(`<comments xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><authors><author>exl</author></authors><commentList>`)
+	vmlXML.WriteString(`<xml xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:x="urn:schemas-microsoft-com:office:excel">`)
+	fmt.Fprintf(vmlXML, `<o:shapelayout v:ext="edit"><o:idmap v:ext="edit" data="%d"/></o:shapelayout>`, sheetIndex+1)
+	vmlXML.WriteString(`<v:shapetype id="_x0000_t202" coordsize="21600,21600" o:spt="202" path="m,l,21600r21600,l21600,xe"><v:stroke joinstyle="miter"/><v:path gradientshapeok="t" o:connecttype="rect"/></v:shapetype>`)
+	for i, c := range comments {
+		fmt.Fprintf(commentsXML, `<comment ref="%s" authorId="0"><text><r><t xml:space="preserve">`, xlsx.GetCellIDStringFromCoords(c.col, c.row))
+		_ = xml.EscapeText(commentsXML, []byte(c.text))
+		commentsXML.WriteString(`</t></r></text></comment>`)
+		fmt.Fprintf(vmlXML, `<v:shape id="_x0000_s%d" type="#_x0000_t202" style="position:absolute;margin-left:59.25pt;margin-top:1.5pt;width:144pt;height:60pt;z-index:%d;visibility:hidden" fillcolor="#ffffe1" o:insetmode="auto">`, (sheetIndex+1)*1024+i+1, i+1)
+		vmlXML.WriteString(`<v:fill color2="#ffffe1"/><v:shadow on="t" color="black" obscured="t"/><v:path o:connecttype="none"/><v:textbox style="mso-direction-alt:auto"><div style="text-align:left"></div></v:textbox>`)
+		fmt.Fprintf(vmlXML, `<x:ClientData ObjectType="Note"><x:MoveWithCells/><x:SizeWithCells/><x:Anchor>%d, 15, %d, 2, %d, 15, %d, 16</x:Anchor><x:AutoFill>False</x:AutoFill><x:Row>%d</x:Row><x:Column>%d</x:Column></x:ClientData></v:shape>`,
+			c.col+1, c.row, c.col+3, c.row+3, c.row, c.col)
+	}
+	commentsXML.WriteString(`</commentList></comments>`)
+	vmlXML.WriteString(`</xml>`)
+
+	sheet := sheetPart(sheetIndex)
+	commentsPart := p.freeName("xl/comments%d.xml")
+	vmlPart := p.freeName("xl/drawings/vmlDrawing%d.vml")
+	p.set(commentsPart, []byte(commentsXML.String()))
+	p.set(vmlPart, []byte(vmlXML.String()))
+	p.addContentType(commentsPart, "application/vnd.openxmlformats-officedocument.spreadsheetml.comments+xml")
+	p.addContentType(".vml", "application/vnd.openxmlformats-officedocument.vmlDrawing")
+	if _, err := p.addRelationship(sheet, "comments", commentsPart); err != nil {
+		return err
+	}
+	vmlID, err := p.addRelationship(sheet, "vmlDrawing", vmlPart)
+	if err != nil {
+		return err
+	}
+	return p.insertElement(sheet, fmt.Sprintf(`<legacyDrawing r:id="%s"/>`, vmlID),
+		"<legacyDrawingHF", "<drawingHF", "<picture", "<oleObjects", "<controls", "<webPublishItems", "<tableParts", "<extLst", "</worksheet>")
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+func TestAnnotateErrors(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	data := [][]string{
+		{"Code", "Score", "Grade", "Weight"},
+		{"ABC", "90", "A", "1"},
+		{"", "101", "D", "0.5"},
+		{"AB", "-1", "B", "2"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenFile(testFile)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	_, err = ReadFile[*readValidateTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	var cerr ContentError
+	if !errors.As(err, &cerr) {
+		t.Fatal("test failed: expected ContentError")
+	}
+	cerr.RowErrors = append(cerr.RowErrors, RowError{RowIndex: 1, Err: errors.New("row rejected")})
+	// A cell with two errors gets one comment
+	cerr.FieldErrors = append(cerr.FieldErrors, FieldError{RowIndex: 2, ColumnIndex: 0, ColumnHeader: "Code",
+		Err: DuplicateError{FirstRowIndex: 1, Headers: []string{"Code"}}})
+
+	buf := &bytes.Buffer{}
+	if err = AnnotateErrors[*readValidateTmp](buf, f, cerr); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	af, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := af.Sheets[0]
+	equal(t, 5, sheet.MaxCol)
+	cellValue := func(row, col int) string {
+		cell, _ := sheet.Cell(row, col)
+		return cell.Value
+	}
+	equal(t, AnnotateErrorsHeader, cellValue(0, 4))
+	equal(t, "row rejected", cellValue(1, 4))
+	equal(t, "Code: value is required; Score: value must be at most 100; Grade: value must be one of A, B, C; "+
+		"Code: duplicate value of Code, first used in row 2", cellValue(2, 4))
+	equal(t, "Code: length must be 3; Score: value must be at least 0; Weight: value must be at most 1.5", cellValue(3, 4))
+
+	fillColor := func(row, col int) string {
+		cell, _ := sheet.Cell(row, col)
+		return cell.GetStyle().Fill.FgColor
+	}
+	equal(t, annotateErrorsColor, fillColor(2, 0))
+	equal(t, annotateErrorsColor, fillColor(3, 3))
+	if fillColor(1, 0) == annotateErrorsColor || fillColor(3, 2) == annotateErrorsColor {
+		t.Error("test failed: cell without error is highlighted")
+	}
+
+	// The original file is not modified
+	equal(t, 4, f.Sheets[0].MaxCol)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	parts := make(map[string]string)
+	for _, zf := range zr.File {
+		rc, _ := zf.Open()
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		parts[zf.Name] = string(b)
+	}
+	comments := parts["xl/comments1.xml"]
+	if strings.Count(comments, "<comment ") != 6 || !strings.Contains(comments, `<comment ref="A3" authorId="0"><text><r><t xml:space="preserve">value is required; duplicate value of Code, first used in row 2</t>`) {
+		t.Error("test failed: unexpected comments: " + comments)
+	}
+	if strings.Count(parts["xl/drawings/vmlDrawing1.vml"], "<v:shape ") != 6 {
+		t.Error("test failed: unexpected vml shapes")
+	}
+	if _, ok := parts["xl/drawings/vmlDrawing1.vml"]; !ok {
+		t.Error("test failed: missing vml drawing")
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet1.xml"], "<legacyDrawing r:id=") {
+		t.Error("test failed: missing legacyDrawing")
+	}
+	if !strings.Contains(parts["xl/worksheets/_rels/sheet1.xml.rels"], "comments1.xml") {
+		t.Error("test failed: missing comments relationship")
+	}
+	if !strings.Contains(parts["[Content_Types].xml"], `Extension="vml"`) {
+		t.Error("test failed: missing vml content type")
+	}
+}
+
+func TestAnnotateErrorsNoErrors(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := WriteExcel(testFile, [][]string{{"Code"}, {"ABC"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, _ := xlsx.OpenFile(testFile)
+	buf := &bytes.Buffer{}
+	if err := AnnotateErrors[*readValidateTmp](buf, f, ContentError{}, WithSheetIndex(3)); !errors.Is(err, ErrSheetIndexOutOfRange) {
+		t.Error("test failed: expected ErrSheetIndexOutOfRange")
+	}
+	if err := AnnotateErrors[*readValidateTmp](buf, f, ContentError{}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	af, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	cell, _ := af.Sheets[0].Cell(0, 1)
+	equal(t, AnnotateErrorsHeader, cell.Value)
+}
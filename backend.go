@@ -0,0 +1,188 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"iter"
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	// workbookBackend is a workbook opened for reading by a file format or library.
+	// The rows of its sheets are read as Cell values, so the struct binding
+	// does not depend on the backend, see xlsxWorkbook and streamWorkbook.
+	// Only reading is abstracted: ReadParsed and AnnotateErrors take an *xlsx.File,
+	// and Write, Writer and templates write with the xlsx library.
+	workbookBackend interface {
+		// sheetNames returns the names of the sheets in order.
+		sheetNames() []string
+		// sheet opens the sheet at the 0-based index.
+		sheet(index int) (sheetBackend, error)
+	}
+	// sheetBackend is a sheet to read the rows of.
+	sheetBackend interface {
+		// dimension returns the number of rows and columns of the sheet,
+		// -1 and -1 if they are not known before the rows are read, e.g. of a CSV file.
+		dimension() (maxRow, maxCol int, err error)
+		// date1904 reports whether the workbook uses the 1904 date system.
+		date1904() bool
+		// rows yields the rows in order, up to the dimension.
+		// Rows missing in a sheet with a dimension are yielded as rows without cells.
+		rows() iter.Seq2[sheetRow, error]
+	}
+	// sheetRow is a row of a sheetBackend.
+	sheetRow struct {
+		index int
+		cells []*Cell
+	}
+	// xlsxWorkbook is a workbook parsed by the xlsx library, the default backend.
+	xlsxWorkbook struct {
+		file *xlsx.File
+	}
+	// xlsxSheet is a sheet of an xlsxWorkbook.
+	xlsxSheet struct {
+		sheet  *xlsx.Sheet
+		is1904 bool
+	}
+)
+
+// openSheet opens the sheet of the workbook configured by ReadConfig.SheetName or ReadConfig.SheetIndex.
+func openSheet(wb workbookBackend, rc *ReadConfig) (sheetBackend, error) {
+	sidx, err := sheetIndex(wb.sheetNames(), rc)
+	if err != nil {
+		return nil, err
+	}
+	return wb.sheet(sidx)
+}
+
+// sheetIndex returns the index of the sheet configured by ReadConfig.SheetName or ReadConfig.SheetIndex.
+func sheetIndex(names []string, rc *ReadConfig) (int, error) {
+	sidx := rc.SheetIndex
+	if len(rc.SheetName) > 0 {
+		for idx, name := range names {
+			if name == rc.SheetName {
+				sidx = idx
+				break
+			}
+		}
+	}
+	if sidx < 0 || sidx > len(names)-1 {
+		return 0, ErrSheetIndexOutOfRange
+	}
+	return sidx, nil
+}
+
+// cell returns the cell in the column, or an empty cell.
+func (r sheetRow) cell(columnIndex int) *Cell {
+	if columnIndex < len(r.cells) && r.cells[columnIndex] != nil {
+		return r.cells[columnIndex]
+	}
+	return &Cell{}
+}
+
+// strings returns the values of the first maxCol cells.
+func (r sheetRow) strings(maxCol int) []string {
+	ls := make([]string, maxCol)
+	for i := range ls {
+		ls[i] = r.cell(i).Value
+	}
+	return ls
+}
+
+// parsedWorkbook returns a func opening the parsed file as workbook backend.
+func parsedWorkbook(f *xlsx.File) func(rc *ReadConfig) (workbookBackend, error) {
+	return func(*ReadConfig) (workbookBackend, error) { return xlsxWorkbook{file: f}, nil }
+}
+
+func (wb xlsxWorkbook) sheetNames() []string {
+	names := make([]string, len(wb.file.Sheets))
+	for i, sheet := range wb.file.Sheets {
+		names[i] = sheet.Name
+	}
+	return names
+}
+
+func (wb xlsxWorkbook) sheet(index int) (sheetBackend, error) {
+	return xlsxSheet{sheet: wb.file.Sheets[index], is1904: wb.file.Date1904}, nil
+}
+
+func (s xlsxSheet) dimension() (int, int, error) { return s.sheet.MaxRow, s.sheet.MaxCol, nil }
+
+func (s xlsxSheet) date1904() bool { return s.is1904 }
+
+func (s xlsxSheet) rows() iter.Seq2[sheetRow, error] {
+	return func(yield func(sheetRow, error) bool) {
+		for rowIndex := 0; rowIndex < s.sheet.MaxRow; rowIndex++ {
+			row, err := s.sheet.Row(rowIndex)
+			if err != nil {
+				yield(sheetRow{}, err)
+				return
+			}
+			if !yield(xlsxRow(row, rowIndex, s.sheet.MaxCol, s.is1904), nil) {
+				return
+			}
+		}
+	}
+}
+
+// xlsxRow returns the first maxCol cells of the row.
+func xlsxRow(row *xlsx.Row, rowIndex, maxCol int, date1904 bool) sheetRow {
+	r := sheetRow{index: rowIndex, cells: make([]*Cell, maxCol)}
+	for columnIndex := range r.cells {
+		r.cells[columnIndex] = cellFromXLSX(row.GetCell(columnIndex), date1904)
+	}
+	return r
+}
+
+// cellFromXLSX copies the value, type, number format and formula of the cell.
+func cellFromXLSX(x *xlsx.Cell, date1904 bool) *Cell {
+	c := &Cell{Value: x.Value, NumFmt: x.NumFmt, formula: x.Formula(), date1904: date1904}
+	if c.Value == "" && len(x.RichText) > 0 {
+		var b strings.Builder
+		for _, run := range x.RichText {
+			b.WriteString(run.Text)
+		}
+		c.Value = b.String()
+	}
+	switch x.Type() {
+	case xlsx.CellTypeNumeric:
+		c.cellType = CellTypeNumeric
+	case xlsx.CellTypeBool:
+		c.cellType = CellTypeBool
+	case xlsx.CellTypeDate:
+		c.cellType = CellTypeDate
+	case xlsx.CellTypeError:
+		c.cellType = CellTypeError
+	}
+	return c
+}
+
+// copyTo sets the value, type, number format and formula of the xlsx.Cell to the ones of the cell.
+func (c *Cell) copyTo(x *xlsx.Cell) {
+	switch c.cellType {
+	case CellTypeNumeric:
+		x.SetNumeric(c.Value)
+	case CellTypeBool:
+		x.SetBool(false)
+		x.Value = c.Value
+	default:
+		x.SetString(c.Value)
+	}
+	if c.formula != "" && c.cellType == CellTypeString {
+		x.SetStringFormula(c.formula)
+	} else if c.formula != "" {
+		x.SetFormula(c.formula)
+	}
+	x.NumFmt = c.NumFmt
+}
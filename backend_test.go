@@ -0,0 +1,135 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+func TestBackends(t *testing.T) {
+	data := readStreamFile(t, false)
+	f, err := xlsx.OpenBinary(data)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	stream, err := openStreamWorkbook(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	for _, wb := range []workbookBackend{xlsxWorkbook{file: f}, stream} {
+		equal(t, []string{"Other", "Data"}, wb.sheetNames())
+		_, err = openSheet(wb, &ReadConfig{SheetName: "Missing", SheetIndex: 2})
+		equal(t, ErrSheetIndexOutOfRange, err)
+
+		sheet, err := openSheet(wb, &ReadConfig{SheetName: "Data"})
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		maxRow, maxCol, err := sheet.dimension()
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []int{4, 7}, []int{maxRow, maxCol})
+		if s, ok := sheet.(*streamSheet); ok {
+			// The rows do not compute the dimension again
+			equal(t, true, s.hasDimension)
+		}
+		var indexes []int
+		var values [][]string
+		var formulas []string
+		for row, err := range sheet.rows() {
+			if err != nil {
+				t.Fatal("test failed: " + err.Error())
+			}
+			indexes = append(indexes, row.index)
+			values = append(values, row.strings(2))
+			formulas = append(formulas, row.cell(0).Formula())
+		}
+		equal(t, []int{0, 1, 2, 3}, indexes)
+		equal(t, [][]string{{"Name", "Count"}, {"apple", "3"}, {"", ""}, {"pear", "-1"}}, values)
+		equal(t, []string{"", "", "", `"pe"&"ar"`}, formulas)
+	}
+}
+
+func TestBackendsSharedFormula(t *testing.T) {
+	f := xlsx.NewFile()
+	sheet, _ := f.AddSheet("Data")
+	for i := 1; i <= 3; i++ {
+		row := sheet.AddRow()
+		row.AddCell().SetInt(i)
+		row.AddCell().SetFormula(fmt.Sprintf("A%d*$A$1", i))
+	}
+	buf := &bytes.Buffer{}
+	if err := writePackage(f, buf, func(p *xlsxPackage) error {
+		// The xlsx library does not write shared formulas
+		part := string(p.parts["xl/worksheets/sheet1.xml"])
+		part = strings.Replace(part, "<f>A1*$A$1</f>", `<f t="shared" ref="B1:B3" si="0">A1*$A$1</f>`, 1)
+		part = strings.Replace(part, "<f>A2*$A$1</f>", `<f t="shared" si="0"/>`, 1)
+		part = strings.Replace(part, "<f>A3*$A$1</f>", `<f t="shared" si="0"></f>`, 1)
+		p.set("xl/worksheets/sheet1.xml", []byte(part))
+		return nil
+	}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	data := buf.Bytes()
+	parsed, err := xlsx.OpenBinary(data)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	stream, err := openStreamWorkbook(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	for _, wb := range []workbookBackend{xlsxWorkbook{file: parsed}, stream} {
+		sheet, err := wb.sheet(0)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		var formulas []string
+		for row, err := range sheet.rows() {
+			if err != nil {
+				t.Fatal("test failed: " + err.Error())
+			}
+			formulas = append(formulas, row.cell(1).Formula())
+		}
+		equal(t, []string{"A1*$A$1", "A2*$A$1", "A3*$A$1"}, formulas)
+	}
+}
+
+func TestCellCopyTo(t *testing.T) {
+	cell := &Cell{}
+	cell.SetFloatWithFormat(1.5, "0.00")
+	cell.SetFormula("A1*2")
+	x := &xlsx.Cell{}
+	cell.copyTo(x)
+	equal(t, "1.5", x.Value)
+	equal(t, "0.00", x.NumFmt)
+	equal(t, "A1*2", x.Formula())
+	equal(t, xlsx.CellTypeNumeric, x.Type())
+	equal(t, cell, cellFromXLSX(x, false))
+
+	// String formulas keep their type
+	x = &xlsx.Cell{}
+	x.SetStringFormula(`"pe"&"ar"`)
+	x.Value = "pear"
+	cell = cellFromXLSX(x, false)
+	equal(t, CellTypeString, cell.Type())
+	x = &xlsx.Cell{}
+	cell.copyTo(x)
+	equal(t, xlsx.CellTypeStringFormula, x.Type())
+	equal(t, "pear", x.Value)
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"strconv"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// date1904Offset is the serial number of 1904-01-01 in the 1900 date system.
+const date1904Offset = 1462
+
+type (
+	// CellType is the type of the value stored in a Cell.
+	CellType int
+	// Cell is a cell as read from a sheet, passed to the unmarshalers,
+	// or as written, passed to the marshalers.
+	// It does not depend on the library reading or writing the file,
+	// see ReadStream for reading without loading the whole workbook.
+	Cell struct {
+		// The raw value as stored in the file, e.g. the serial number of a date.
+		// The cached value of a formula.
+		Value string
+		// The number format of the cell style, e.g. "0.00" or "yyyy-mm-dd".
+		NumFmt   string
+		cellType CellType
+		formula  string
+		date1904 bool
+		// The value is text without a type, e.g. a field of a CSV file
+		untyped bool
+	}
+)
+
+const (
+	CellTypeString CellType = iota
+	CellTypeNumeric
+	CellTypeBool
+	// CellTypeDate holds a date in ISO 8601 format, rarely used by Excel.
+	CellTypeDate
+	// CellTypeError holds the error of a formula, e.g. "#DIV/0!".
+	CellTypeError
+)
+
+// toXLSX returns an xlsx.Cell with the value, type and number format of the cell,
+// to reuse the number formatting of the xlsx library.
+func (c *Cell) toXLSX() *xlsx.Cell {
+	x := &xlsx.Cell{}
+	value := *c
+	value.formula = ""
+	value.copyTo(x)
+	return x
+}
+
+// set sets the cell to the value set by the func on an xlsx.Cell,
+// keeping the number format unless the func changes it.
+func (c *Cell) set(set func(x *xlsx.Cell)) {
+	x := &xlsx.Cell{NumFmt: c.NumFmt}
+	set(x)
+	*c = *cellFromXLSX(x, c.date1904)
+}
+
+// Type returns the type of the value.
+func (c *Cell) Type() CellType { return c.cellType }
+
+// SetString sets the cell to a string.
+func (c *Cell) SetString(s string) { c.set(func(x *xlsx.Cell) { x.SetString(s) }) }
+
+// SetNumeric sets the cell to a number, given as string.
+func (c *Cell) SetNumeric(s string) { c.set(func(x *xlsx.Cell) { x.SetNumeric(s) }) }
+
+// SetBool sets the cell to a boolean.
+func (c *Cell) SetBool(b bool) { c.set(func(x *xlsx.Cell) { x.SetBool(b) }) }
+
+// SetDate sets the cell to a date, formatted as date.
+func (c *Cell) SetDate(t time.Time) { c.set(func(x *xlsx.Cell) { x.SetDate(t) }) }
+
+// SetDateTime sets the cell to a date and time, formatted as date and time.
+func (c *Cell) SetDateTime(t time.Time) { c.set(func(x *xlsx.Cell) { x.SetDateTime(t) }) }
+
+// SetFloatWithFormat sets the cell to a number with the number format.
+func (c *Cell) SetFloatWithFormat(n float64, format string) {
+	c.set(func(x *xlsx.Cell) { x.SetFloatWithFormat(n, format) })
+}
+
+// SetValue sets the cell to a value of any type, like xlsx.Cell.SetValue.
+func (c *Cell) SetValue(n any) { c.set(func(x *xlsx.Cell) { x.SetValue(n) }) }
+
+// SetFormula sets the formula of a numeric cell.
+// The value is kept as the cached value of the formula.
+func (c *Cell) SetFormula(formula string) {
+	c.formula = formula
+	c.cellType = CellTypeNumeric
+}
+
+// Formula returns the formula of the cell, "" if it has none.
+func (c *Cell) Formula() string { return c.formula }
+
+// Bool returns the value as boolean.
+// Numbers are true unless they are 0, other values unless they are empty.
+// Fields of CSV files like "false" or "0" are false, see strconv.ParseBool.
+func (c *Cell) Bool() bool {
+	if c.untyped {
+		if b, err := strconv.ParseBool(c.Value); err == nil {
+			return b
+		}
+	}
+	return c.toXLSX().Bool()
+}
+
+// Int returns the value as integer, truncating decimals.
+func (c *Cell) Int() (int, error) { return c.toXLSX().Int() }
+
+// Int64 returns the value as 64-bit integer.
+func (c *Cell) Int64() (int64, error) { return c.toXLSX().Int64() }
+
+// Float returns the value as number.
+func (c *Cell) Float() (float64, error) { return c.toXLSX().Float() }
+
+// IsTime reports whether the cell is formatted as date or time.
+func (c *Cell) IsTime() bool { return c.toXLSX().IsTime() }
+
+// GetTime returns the value as time, converted from the serial number
+// of the 1900 or 1904 date system.
+func (c *Cell) GetTime(date1904 bool) (time.Time, error) { return c.toXLSX().GetTime(date1904) }
+
+// FormattedValue returns the value formatted with the number format,
+// as displayed by Excel. If the format is not supported,
+// the raw value is returned with an error.
+func (c *Cell) FormattedValue() (string, error) {
+	switch c.cellType {
+	case CellTypeDate, CellTypeError:
+		return c.Value, nil
+	}
+	x := c.toXLSX()
+	if c.date1904 && c.cellType == CellTypeNumeric && x.IsTime() {
+		// The xlsx library formats in the 1900 date system
+		if f, err := x.Float(); err == nil {
+			x.Value = strconv.FormatFloat(f+date1904Offset, 'f', -1, 64)
+		}
+	}
+	return x.FormattedValue()
+}
+
+// String returns the formatted value, see FormattedValue.
+func (c *Cell) String() string {
+	value, _ := c.FormattedValue()
+	return value
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"testing"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+func TestCellFromXLSX(t *testing.T) {
+	x := &xlsx.Cell{}
+	x.SetRichText([]xlsx.RichTextRun{{Text: "rich "}, {Text: "text"}})
+	c := cellFromXLSX(x, false)
+	equal(t, "rich text", c.Value)
+	equal(t, CellTypeString, c.Type())
+
+	x.SetFloatWithFormat(0.5, "0%")
+	c = cellFromXLSX(x, false)
+	equal(t, CellTypeNumeric, c.Type())
+	equal(t, "50%", c.String())
+
+	x.SetBool(true)
+	c = cellFromXLSX(x, false)
+	equal(t, CellTypeBool, c.Type())
+	equal(t, "TRUE", c.String())
+}
+
+func TestCellValues(t *testing.T) {
+	c := &Cell{}
+	c.SetValue(12)
+	equal(t, CellTypeNumeric, c.Type())
+	equal(t, true, c.Bool())
+	i, err := c.Int64()
+	equal(t, nil, err)
+	equal(t, int64(12), i)
+
+	c.SetNumeric("0")
+	equal(t, false, c.Bool())
+
+	c.SetString("")
+	equal(t, false, c.Bool())
+	// Text cells are true unless empty, fields of CSV files are parsed
+	c.SetString("FALSE")
+	equal(t, true, c.Bool())
+	equal(t, false, (&Cell{Value: "FALSE", untyped: true}).Bool())
+	equal(t, false, (&Cell{Value: "0", untyped: true}).Bool())
+	equal(t, true, (&Cell{Value: "yes", untyped: true}).Bool())
+	_, err = c.Float()
+	if err == nil {
+		t.Error("test failed: expected error parsing an empty cell")
+	}
+
+	c = &Cell{Value: "#DIV/0!", NumFmt: "0.00", cellType: CellTypeError}
+	equal(t, "#DIV/0!", c.String())
+	c = &Cell{Value: "2022-05-06T00:00:00Z", cellType: CellTypeDate}
+	equal(t, "2022-05-06T00:00:00Z", c.String())
+}
+
+func TestCellDate1904(t *testing.T) {
+	c := &Cell{date1904: true}
+	c.SetDate(time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC))
+	c.NumFmt = "yyyy-mm-dd"
+	// The serial number of the 1900 date system is 1462 days later in the 1904 one
+	equal(t, "2026-05-07", c.String())
+	tm, err := c.GetTime(true)
+	equal(t, nil, err)
+	equal(t, time.Date(2026, 5, 7, 0, 0, 0, 0, time.UTC), tm)
+	c.date1904 = false
+	equal(t, "2022-05-06", c.String())
+}
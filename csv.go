@@ -0,0 +1,230 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+type (
+	// CSVConfig configures reading and writing CSV files,
+	// see ReadConfig.CSV and WriteConfig.CSV.
+	CSVConfig struct {
+		// The field delimiter, e.g. '\t' for TSV or ';'.
+		// Defaults to 0, a comma, or a tab for files ending in ".tsv".
+		Delimiter rune
+		// The character encoding of the file, e.g. simplifiedchinese.GBK or simplifiedchinese.GB18030.
+		// When reading, a byte order mark takes precedence, so UTF-8 and UTF-16 files with BOM are always read.
+		// Defaults to nil, UTF-8.
+		Encoding encoding.Encoding
+		// Write a UTF-8 byte order mark, which Excel needs to recognize UTF-8 files.
+		// Not used when reading, or with an Encoding.
+		// Defaults to false.
+		BOM bool
+	}
+	// csvWorkbook is a CSV file, a workbook with a single sheet.
+	// The records are read while the rows are iterated, so the dimension is unknown.
+	csvWorkbook struct {
+		r *csv.Reader
+	}
+)
+
+// ReadCSV reads a CSV file from the given io.Reader.
+// Each row is parsed and unmarshalled into a slice of `T`, like ReadParsed,
+// with the same tags, ReadConfig and unmarshal funcs.
+// All cells are read as text, see ReadConfig.FallbackDateFormats for dates.
+// The sheet settings of the ReadConfig are not used, see ReadConfig.CSV for the delimiter and the encoding.
+// Records are read one at a time, the row indexes, e.g. of a FieldError, are the 0-based lines they start on.
+func ReadCSV[T any](reader io.Reader, opts ...ReadOption) ([]T, error) {
+	return collectRows(RowsCSV[T](reader, opts...))
+}
+
+// ReadCSVFile reads a CSV file at the given file path, see ReadCSV.
+// Files ending in ".tsv" are read tab-separated, unless ReadConfig.CSV sets a delimiter.
+func ReadCSVFile[T any](file string, opts ...ReadOption) ([]T, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return collectRows(rowsCSV[T](f, csvFileDelimiter(file), opts...))
+}
+
+// RowsCSV returns an iterator over the rows of a CSV file read from the given io.Reader,
+// see ReadCSV and Rows. The file is read when the iteration starts.
+func RowsCSV[T any](reader io.Reader, opts ...ReadOption) iter.Seq2[T, error] {
+	return rowsCSV[T](reader, ',', opts...)
+}
+
+// rowsCSV reads the CSV file with the delimiter, unless ReadConfig.CSV sets one.
+func rowsCSV[T any](reader io.Reader, delimiter rune, opts ...ReadOption) iter.Seq2[T, error] {
+	// A CSV file has a single sheet
+	opts = append(opts, WithSheetIndex(0))
+	return readSheet[T](context.Background(), func(rc *ReadConfig) (workbookBackend, error) {
+		cc := rc.CSV
+		if cc.Delimiter == 0 {
+			cc.Delimiter = delimiter
+		}
+		return readCSVWorkbook(reader, cc)
+	}, opts...)
+}
+
+// csvFileDelimiter returns the default delimiter by the extension of the file.
+func csvFileDelimiter(file string) rune {
+	if strings.EqualFold(filepath.Ext(file), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+// readCSVWorkbook returns the CSV file, decoded while its records are read.
+// Records may have different numbers of fields.
+func readCSVWorkbook(reader io.Reader, cc CSVConfig) (*csvWorkbook, error) {
+	var fallback transform.Transformer = encoding.Nop.NewDecoder()
+	if cc.Encoding != nil {
+		fallback = cc.Encoding.NewDecoder()
+	}
+	r := csv.NewReader(transform.NewReader(reader, unicode.BOMOverride(fallback)))
+	r.Comma = cc.Delimiter
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	return &csvWorkbook{r: r}, nil
+}
+
+func (wb *csvWorkbook) sheetNames() []string { return []string{"Sheet1"} }
+
+func (wb *csvWorkbook) sheet(int) (sheetBackend, error) { return wb, nil }
+
+func (wb *csvWorkbook) dimension() (maxRow, maxCol int, err error) { return -1, -1, nil }
+
+func (wb *csvWorkbook) date1904() bool { return false }
+
+// rows yields the records with the 0-based line they start on as row index,
+// so the row indexes of errors point to the lines of the file, even after fields spanning lines.
+func (wb *csvWorkbook) rows() iter.Seq2[sheetRow, error] {
+	return func(yield func(sheetRow, error) bool) {
+		for {
+			record, err := wb.r.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(sheetRow{}, err)
+				return
+			}
+			line, _ := wb.r.FieldPos(0)
+			r := sheetRow{index: line - 1, cells: make([]*Cell, len(record))}
+			for columnIndex, value := range record {
+				r.cells[columnIndex] = &Cell{Value: value, NumFmt: "general", untyped: true}
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+// WriteCSV writes the rows to the CSV file, with the same tags and WriteConfig as Write.
+// Cells are written as formatted by Excel, e.g. with the tag option "format",
+// formula columns as their cached values.
+// Styles, dropdowns and the totals row are not written, see WriteConfig.CSV for the delimiter and the encoding.
+// Files ending in ".tsv" are written tab-separated, unless WriteConfig.CSV sets a delimiter.
+func WriteCSV[T WriteConfigurator](file string, ts []T) error {
+	target, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = writeCSV(target, ts, csvFileDelimiter(file)); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// WriteCSVTo writes the rows to w as CSV, see WriteCSV.
+func WriteCSVTo[T WriteConfigurator](w io.Writer, ts []T) error {
+	return writeCSV(w, ts, ',')
+}
+
+// writeCSV writes the header and the rows with the delimiter, unless WriteConfig.CSV sets one.
+func writeCSV[T WriteConfigurator](w io.Writer, ts []T, delimiter rune) error {
+	wc := defaultWriteConfig()
+	tT := new(T)
+	(*tT).WriteConfigure(wc)
+	columns, err := writeColumns(reflect.TypeOf(tT).Elem().Elem(), wc)
+	if err != nil {
+		return err
+	}
+	var encoder *transform.Writer
+	if wc.CSV.Encoding != nil {
+		encoder = transform.NewWriter(w, wc.CSV.Encoding.NewEncoder())
+		w = encoder
+	} else if wc.CSV.BOM {
+		if _, err = io.WriteString(w, "\uFEFF"); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	if wc.CSV.Delimiter != 0 {
+		cw.Comma = wc.CSV.Delimiter
+	}
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.header
+	}
+	if err = cw.Write(record); err != nil {
+		return err
+	}
+	for i, t := range ts {
+		cells, err := marshalRow(columns, i+1, reflect.ValueOf(t).Elem())
+		if err != nil {
+			return err
+		}
+		for columnIndex, cell := range cells {
+			if format := columns[columnIndex].style.Format; format != "" {
+				cell.NumFmt = format
+			}
+			record[columnIndex] = csvValue(cell)
+		}
+		if err = cw.Write(record); err != nil {
+			return err
+		}
+		if wc.Progress != nil {
+			wc.Progress(i+1, len(ts))
+		}
+	}
+	cw.Flush()
+	if err = cw.Error(); err != nil || encoder == nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// csvValue returns the formatted value of the cell, or its raw value if the format is not supported.
+func csvValue(cell *Cell) string {
+	if value, err := cell.FormattedValue(); err == nil {
+		return value
+	}
+	return cell.Value
+}
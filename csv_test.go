@@ -0,0 +1,220 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type csvTmp struct {
+	Name    string    `excel:"Name"`
+	Count   int       `excel:"Count"`
+	Amount  float64   `excel:"Amount,format=0.00"`
+	Active  bool      `excel:"Active"`
+	Created time.Time `excel:"Created,format=yyyy-mm-dd"`
+	Total   float64   `excel:"Total,formula={Amount}*{Count}"`
+}
+
+func (*csvTmp) WriteConfigure(_ *WriteConfig) {}
+
+func (*csvTmp) ReadConfigure(rc *ReadConfig) {
+	rc.FallbackDateFormats = []string{"2006-01-02"}
+}
+
+type csvGBKTmp struct {
+	Name string `excel:"名称"`
+	City string `excel:"城市"`
+}
+
+func (*csvGBKTmp) WriteConfigure(wc *WriteConfig) {
+	wc.CSV.Encoding = simplifiedchinese.GBK
+	wc.CSV.Delimiter = ';'
+}
+
+type csvBOMTmp struct {
+	Name string `excel:"Name"`
+}
+
+func (*csvBOMTmp) WriteConfigure(wc *WriteConfig) { wc.CSV.BOM = true }
+
+var csvTmpData = []*csvTmp{
+	{"apple", 3, 1.5, true, time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC), 0},
+	{"pear, green", 2, 0.25, false, time.Date(2022, 5, 7, 0, 0, 0, 0, time.UTC), 0},
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteCSVTo(buf, csvTmpData); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, "Name,Count,Amount,Active,Created,Total\n"+
+		"apple,3,1.50,TRUE,2022-05-06,4.5\n"+
+		"\"pear, green\",2,0.25,FALSE,2022-05-07,0.5\n", buf.String())
+
+	models, err := ReadCSV[*csvTmp](buf)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 2, len(models))
+	for i, m := range models {
+		equal(t, csvTmpData[i].Name, m.Name)
+		equal(t, csvTmpData[i].Count, m.Count)
+		equal(t, csvTmpData[i].Amount, m.Amount)
+		equal(t, csvTmpData[i].Active, m.Active)
+		equal(t, csvTmpData[i].Created, m.Created)
+		equal(t, m.Amount*float64(m.Count), m.Total)
+	}
+}
+
+func TestCSVFile(t *testing.T) {
+	file := path.Join(t.TempDir(), "tmp.tsv")
+	if err := WriteCSV(file, csvTmpData); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, "Name\tCount\tAmount\tActive\tCreated\tTotal", strings.Split(string(data), "\n")[0])
+	models, err := ReadCSVFile[*csvTmp](file)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, "pear, green", models[1].Name)
+
+	if _, err = ReadCSVFile[*csvTmp](path.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("test failed: expected error reading a missing file")
+	}
+}
+
+func TestCSVEncoding(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteCSVTo(buf, []*csvGBKTmp{{"苹果", "北京"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("名称;城市\n苹果;北京\n")
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, gbk, buf.String())
+
+	models, err := ReadCSV[*csvGBKTmp](buf, WithCSVEncoding(simplifiedchinese.GB18030), WithCSVDelimiter(';'))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []*csvGBKTmp{{"苹果", "北京"}}, models)
+}
+
+func TestCSVBOM(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteCSVTo(buf, []*csvBOMTmp{{"苹果"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, "\uFEFFName\n苹果\n", buf.String())
+	// The byte order mark takes precedence over the encoding
+	models, err := ReadCSV[*csvBOMTmp](buf, WithCSVEncoding(simplifiedchinese.GBK))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []*csvBOMTmp{{"苹果"}}, models)
+}
+
+func TestReadCSVConfig(t *testing.T) {
+	data := "Report,\n" +
+		"Name,Count,Amount\n" +
+		"  apple  ,3,1.5\n" +
+		"pear,x,1\n" +
+		"plum,y,z\n"
+	models, err := ReadCSV[*csvTmp](strings.NewReader(data), WithHeaderRow(1), WithTrimSpace(true),
+		WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 3, len(models))
+	equal(t, "apple", models[0].Name)
+	equal(t, 3, models[0].Count)
+	equal(t, 1.0, models[1].Amount)
+
+	_, err = ReadCSV[*csvTmp](strings.NewReader(data), WithHeaderRow(1), WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	var cerr ContentError
+	if !errors.As(err, &cerr) {
+		t.Fatal("test failed: expected ContentError")
+	}
+	var fieldErrors []string
+	for _, fieldError := range cerr.FieldErrors {
+		fieldErrors = append(fieldErrors, fieldError.ColumnHeader)
+	}
+	equal(t, []string{"Count", "Count", "Amount"}, fieldErrors)
+
+	// The sheet settings do not apply
+	models, err = ReadCSV[*csvTmp](strings.NewReader(data), WithHeaderRow(1), WithSheetName("Data"),
+		WithReadConfig(func(rc *ReadConfig) { rc.SheetIndex = 3 }), WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 3, len(models))
+
+	_, err = ReadCSV[*csvTmp](strings.NewReader("Name\n\"apple"))
+	if err == nil {
+		t.Error("test failed: expected error reading invalid CSV")
+	}
+}
+
+func TestReadCSVLines(t *testing.T) {
+	data := "Name,Count\n" +
+		"\"apple\nred\",3\n" +
+		"pear,x\n"
+	var totals []int
+	_, err := ReadCSV[*csvTmp](strings.NewReader(data), WithUnmarshalErrorHandling(UnmarshalErrorCollect),
+		WithProgress(func(_, total int) { totals = append(totals, total) }))
+	var cerr ContentError
+	if !errors.As(err, &cerr) {
+		t.Fatal("test failed: expected ContentError")
+	}
+	// The row index is the line of the record, after the field spanning two lines
+	equal(t, 1, len(cerr.FieldErrors))
+	equal(t, 3, cerr.FieldErrors[0].RowIndex)
+	// The number of rows is unknown before the file is read
+	equal(t, []int{-1, -1}, totals)
+
+	// Records are read one by one, the rows before an invalid record are yielded
+	var names []string
+	for model, err := range RowsCSV[*csvTmp](strings.NewReader("Name\napple\npe\"ar\n")) {
+		if err != nil {
+			break
+		}
+		names = append(names, model.Name)
+	}
+	equal(t, []string{"apple"}, names)
+
+	models, err := ReadCSV[*csvTmp](strings.NewReader("apple,3\npear,2\n"), WithNoHeaderRow(), WithDataStartRow(0))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 2, len(models))
+	equal(t, 2, models[1].Count)
+
+	for _, opt := range []ReadOption{WithDataStartRow(4), WithHeaderRow(4)} {
+		_, err = ReadCSV[*csvTmp](strings.NewReader(data), opt)
+		if !errors.Is(err, ErrDataStartRowIndexOutOfRange) && !errors.Is(err, ErrHeaderRowIndexOutOfRange) {
+			t.Error("test failed: expected out of range error, got:", err)
+		}
+	}
+}
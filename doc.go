@@ -11,5 +11,49 @@
 
 // Package exl
 //
-// Excel binding to struct written in Go.(Only supports Go1.18+)
+// Excel binding to struct written in Go.(Only supports Go1.23+)
+//
+// # Struct tags
+//
+// The tag (default "excel") holds the header of a field, followed by options:
+//
+//	`excel:"Header,option,key=value"`
+//
+// Options understood when reading:
+//
+//	prefix=P    header prefix of the fields of a nested struct, default "Field."
+//	alias=A|B   further headers the field binds to
+//	col=C       bind to a column by its letters
+//	idx=2       bind to a column by its 0-based index
+//	required    fail with a HeaderError if the sheet has no column for the field
+//	unique[=K]  reject repeated values, fields sharing a key K form a composite key
+//	enum=A|B    reject values other than the listed ones, empty cells are accepted
+//
+// Options understood when writing:
+//
+//	format=F    number format of the cells, e.g. "#,##0.00"
+//	width=14    column width
+//	align=A     horizontal alignment, left, center or right
+//	wrap        wrap text
+//	enum=A|B    dropdown of the listed values
+//	list=L      list the dropdown values in the hidden sheet L, for long lists
+//	formula=F   write the formula instead of the field, "{row}" is the row number,
+//	            "{Header}" the cell of another column in the row, e.g. "{Price}*{Qty}"
+//	total=FN    function of the totals row, see WriteConfig.TotalsRow
+//
+// The header row is styled with WriteConfig.HeaderStyle.
+//
+// Validation rules are listed in their own tag (default "validate"),
+// see ReadConfig.ValidateTagName.
+//
+// # Custom types
+//
+// Fields implementing ExcelUnmarshaler and ExcelMarshaler read and write their cells themselves,
+// otherwise encoding.TextUnmarshaler and encoding.TextMarshaler are used, if implemented.
+// Other fields fall back to DefaultUnmarshalFuncs and DefaultMarshalFuncs by their kind.
+// Marshalers and unmarshalers get the cell as Cell, whichever library or format
+// reads or writes the file. Only ReadParsed, Rows, AnnotateErrors and Template.File
+// take or return the workbook of the xlsx library, for interoperability.
+// For types you don't own, register unmarshal funcs for a single read call
+// in ReadConfig.UnmarshalFuncs, e.g. with WithUnmarshalFunc.
 package exl
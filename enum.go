@@ -0,0 +1,166 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// maxDropListLength is the maximum length of the formula of a dropdown with inline values.
+const maxDropListLength = 255
+
+// tagEnum returns the allowed values of the tag option "enum", e.g. `excel:"Status,enum=Active|Closed"`.
+func tagEnum(ft fieldTag) []string {
+	enum, ok := ft.Options["enum"]
+	if !ok || enum == "" {
+		return nil
+	}
+	return strings.Split(enum, "|")
+}
+
+// enumValidator rejects values which are not listed in the enum with a ValidationError.
+// Empty cells are accepted, combine it with the rule required to reject them.
+// Other cells are checked even if they hold a zero value, e.g. "0" for enum=1|2|3.
+func enumValidator(values []string) validateFunc {
+	fail := ValidationError{Rule: "enum", Param: strings.Join(values, "|")}
+	return func(v reflect.Value, empty bool) error {
+		if empty {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		if !v.CanInterface() || !slices.Contains(values, fmt.Sprint(v.Interface())) {
+			return fail
+		}
+		return nil
+	}
+}
+
+// checkDropdown reports an error if the enum of the column cannot be written as dropdown.
+// Values are listed inline, unless the tag option "list" names a lookup sheet for them.
+func checkDropdown(column writeColumn, fieldName string) error {
+	if column.list != "" && column.enum == nil {
+		return fmt.Errorf("%w list=%s on field %s without enum", ErrInvalidTag, column.list, fieldName)
+	}
+	if column.list == "" && column.enum != nil {
+		inline := strings.Join(column.enum, ",")
+		if strings.ContainsAny(strings.Join(column.enum, ""), `,"`) || utf8.RuneCountInString(inline)+2 > maxDropListLength {
+			return fmt.Errorf("%w enum=%s on field %s, too long or with commas, set list=Sheet", ErrInvalidTag, strings.Join(column.enum, "|"), fieldName)
+		}
+	}
+	return nil
+}
+
+// enumMessage is the error message of a dropdown.
+func enumMessage(values []string) string {
+	return ValidationError{Rule: "enum", Param: strings.Join(values, "|")}.Error()
+}
+
+// quoteSheetName quotes a sheet name for a formula.
+func quoteSheetName(name string) string {
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// addDropdowns adds a dropdown to the data rows below the header row
+// for every column with an enum.
+// Dropdowns of rows written before into the same sheet end above the header row.
+func addDropdowns(f *xlsx.File, sheet *xlsx.Sheet, columns []writeColumn, headerRow int) error {
+	for columnIndex, column := range columns {
+		if column.enum == nil {
+			continue
+		}
+		letters := xlsx.ColIndexToLetters(columnIndex)
+		dvs := sheet.DataValidations[:0]
+		for _, dv := range sheet.DataValidations {
+			if start, ok := strings.CutSuffix(dv.Sqref, fmt.Sprintf(":%s%d", letters, xlsx.Excel2006MaxRowCount)); ok {
+				if _, startRow, err := xlsx.GetCoordsFromCellIDString(start); err == nil && startRow >= headerRow {
+					// No rows were written below the previous header
+					continue
+				}
+				dv.Sqref = fmt.Sprintf("%s:%s%d", start, letters, headerRow)
+			}
+			dvs = append(dvs, dv)
+		}
+		sheet.DataValidations = dvs
+		dv := xlsx.NewDataValidation(headerRow+1, columnIndex, xlsx.Excel2006MaxRowIndex, columnIndex, true)
+		if column.list != "" {
+			lookupIndex, err := lookupColumn(f, column.list, column.enum)
+			if err != nil {
+				return err
+			}
+			if err = dv.SetInFileList(column.list, lookupIndex, 0, lookupIndex, len(column.enum)-1); err != nil {
+				return err
+			}
+		} else if err := dv.SetDropList(column.enum); err != nil {
+			return err
+		}
+		msg := enumMessage(column.enum)
+		dv.SetError(xlsx.StyleStop, nil, &msg)
+		sheet.AddDataValidation(dv)
+	}
+	return nil
+}
+
+// lookupColumn returns the column of the hidden lookup sheet which lists the values,
+// adding the sheet or the column if needed.
+func lookupColumn(f *xlsx.File, name string, values []string) (int, error) {
+	sheet, ok := f.Sheet[name]
+	if !ok {
+		var err error
+		if sheet, err = f.AddSheet(name); err != nil {
+			return 0, err
+		}
+		sheet.Hidden = true
+	}
+	for columnIndex := 0; columnIndex < sheet.MaxCol; columnIndex++ {
+		listed := true
+		for rowIndex := 0; rowIndex < max(sheet.MaxRow, len(values)) && listed; rowIndex++ {
+			value := ""
+			if rowIndex < len(values) {
+				value = values[rowIndex]
+			}
+			if rowIndex < sheet.MaxRow {
+				cell, err := sheet.Cell(rowIndex, columnIndex)
+				if err != nil {
+					return 0, err
+				}
+				listed = cell.Value == value
+			} else {
+				listed = value == ""
+			}
+		}
+		if listed {
+			return columnIndex, nil
+		}
+	}
+	columnIndex := sheet.MaxCol
+	for rowIndex, value := range values {
+		cell, err := sheet.Cell(rowIndex, columnIndex)
+		if err != nil {
+			return 0, err
+		}
+		cell.SetString(value)
+	}
+	// Sheet.Cell does not count the columns
+	sheet.MaxCol = columnIndex + 1
+	return columnIndex, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"slices"
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	enumTmp struct {
+		ID      int    `excel:"ID"`
+		Status  string `excel:"Status,enum=Active|Suspended|Closed"`
+		Country string `excel:"Country,enum=China|France|Germany,list=Lists"`
+		Region  string `excel:"Region,enum=China|France|Germany,list=Lists"`
+	}
+	enumInvalidTmp struct {
+		Note string `excel:"Note,enum=A,B|C"`
+	}
+	enumListTmp struct {
+		Note string `excel:"Note,list=Lists"`
+	}
+	enumLevelTmp struct {
+		Level int `excel:"Level,enum=1|2|3"`
+	}
+)
+
+func (*enumTmp) WriteConfigure(wc *WriteConfig) { wc.SheetName = "Users" }
+func (*enumTmp) ReadConfigure(rc *ReadConfig)   { rc.SheetName = "Users" }
+
+func (*enumInvalidTmp) WriteConfigure(_ *WriteConfig) {}
+func (*enumListTmp) WriteConfigure(_ *WriteConfig)    {}
+func (*enumLevelTmp) ReadConfigure(_ *ReadConfig)     {}
+
+func checkEnumFile(t *testing.T, f *xlsx.File) {
+	t.Helper()
+	sheet := f.Sheet["Users"]
+	equal(t, 2, len(sheet.DataValidations))
+	equal(t, "B2:B1048576", sheet.DataValidations[0].Sqref)
+	equal(t, `"Active,Suspended,Closed"`, sheet.DataValidations[0].Formula1)
+	equal(t, "C2:C1048576", sheet.DataValidations[1].Sqref)
+	equal(t, "'Lists'!$A$1:$A$3", sheet.DataValidations[1].Formula1)
+	lists := f.Sheet["Lists"]
+	equal(t, true, lists.Hidden)
+	equal(t, 1, lists.MaxCol)
+	for i, value := range []string{"China", "France", "Germany"} {
+		cell, _ := lists.Cell(i, 0)
+		equal(t, value, cell.Value)
+	}
+}
+
+func TestWriteEnum(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := Write(testFile, []*enumTmp{{1, "Active", "China", "France"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenFile(testFile)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	// Country and Region share the list
+	sheet := f.Sheet["Users"]
+	equal(t, 3, len(sheet.DataValidations))
+	equal(t, "D2:D1048576", sheet.DataValidations[2].Sqref)
+	equal(t, "'Lists'!$A$1:$A$3", sheet.DataValidations[2].Formula1)
+	sheet.DataValidations = sheet.DataValidations[:2]
+	checkEnumFile(t, f)
+
+	for _, err = range []error{
+		Write(testFile, []*enumInvalidTmp{}),
+		Write(testFile, []*enumListTmp{}),
+	} {
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Error("test failed: expected ErrInvalidTag, got:", err)
+		}
+	}
+}
+
+func TestWriterEnum(t *testing.T) {
+	w := NewWriter()
+	for _, data := range [][]*enumTmp{{{ID: 1}, {ID: 2}}, {}, {{ID: 3}}} {
+		if err := w.Write("Users", data); err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+	}
+	// The dropdowns of the first rows end above the next header
+	sheet := w.file.Sheet["Users"]
+	sqrefs := make([]string, 0, len(sheet.DataValidations))
+	for _, dv := range sheet.DataValidations {
+		sqrefs = append(sqrefs, dv.Sqref)
+	}
+	equal(t, []string{"B2:B3", "C2:C3", "D2:D3", "B6:B1048576", "C6:C1048576", "D6:D1048576"}, sqrefs)
+	equal(t, 1, w.file.Sheet["Lists"].MaxCol)
+}
+
+func TestWriteStreamEnum(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteStreamTo(buf, slices.Values([]*enumTmp{{1, "Active", "China", "France"}})); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f.Sheet["Users"].DataValidations = f.Sheet["Users"].DataValidations[:2]
+	checkEnumFile(t, f)
+}
+
+func TestReadEnum(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	data := [][]string{
+		{"ID", "Status", "Country"},
+		{"1", "Active", "China"},
+		{"2", "Open", ""},
+		{"3", "", "Spain"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	_, err := ReadFile[*enumTmp](testFile, WithSheetName("Sheet1"), WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 2, ColumnIndex: 1, ColumnHeader: "Status", Err: ValidationError{Rule: "enum", Param: "Active|Suspended|Closed"}},
+			{RowIndex: 3, ColumnIndex: 2, ColumnHeader: "Country", Err: ValidationError{Rule: "enum", Param: "China|France|Germany"}},
+		},
+	}, err)
+	equal(t, "value must be one of Active, Suspended, Closed", ValidationError{Rule: "enum", Param: "Active|Suspended|Closed"}.Error())
+}
+
+func TestReadEnumZero(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := WriteExcel(testFile, [][]string{{"Level"}, {"2"}, {"0"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	// The zero value is checked like any other value
+	_, err := ReadFile[*enumLevelTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 2, ColumnIndex: 0, ColumnHeader: "Level", Err: ValidationError{Rule: "enum", Param: "1|2|3"}},
+		},
+	}, err)
+}
@@ -0,0 +1,245 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	// fieldTag is a parsed struct tag of the form `excel:"Header,option,key=value"`.
+	fieldTag struct {
+		Name    string
+		Options map[string]string
+	}
+	// structField is a field of a row type which binds to one column,
+	// possibly promoted from an embedded struct or nested in a struct field.
+	structField struct {
+		index   []int
+		name    string // Go field path, e.g. "Address.City"
+		header  string
+		aliases []string
+		tag     fieldTag
+		rawTag  reflect.StructTag // all tags of the field
+		typ     reflect.Type
+	}
+)
+
+// knownTagOptions lists the option keys recognised after the header name.
+// A comma-separated segment whose key is not listed belongs to the previous segment,
+// so headers and option values may contain commas.
+var knownTagOptions = map[string]bool{
+	"prefix": true,
+	"alias":  true,
+	"col":    true,
+	"idx":    true,
+	"unique": true,
+	"enum":   true,
+	// Write options
+	"format":  true,
+	"width":   true,
+	"align":   true,
+	"list":    true,
+	"formula": true,
+	"total":   true,
+	// Flags
+	"required": true,
+	"wrap":     true,
+}
+
+func parseTag(tag string) fieldTag {
+	segments := strings.Split(tag, ",")
+	ft := fieldTag{Name: segments[0], Options: make(map[string]string)}
+	last := ""
+	// Allow tags without header, e.g. `excel:"col=C"`
+	if key, value, ok := strings.Cut(segments[0], "="); ok && knownTagOptions[key] {
+		ft.Name = ""
+		ft.Options[key] = value
+		last = key
+	}
+	for _, segment := range segments[1:] {
+		key, value, _ := strings.Cut(segment, "=")
+		if knownTagOptions[key] {
+			ft.Options[key] = value
+			last = key
+		} else if last != "" {
+			ft.Options[last] += "," + segment
+		} else {
+			ft.Name += "," + segment
+		}
+	}
+	return ft
+}
+
+// Has reports whether the option is present, with or without value.
+func (ft fieldTag) Has(key string) bool {
+	_, ok := ft.Options[key]
+	return ok
+}
+
+var (
+	timeType             = reflect.TypeOf(time.Time{})
+	excelUnmarshalerType = reflect.TypeOf((*ExcelUnmarshaler)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isNestedStruct reports whether a field of the given type is bound
+// through the fields of the struct instead of being unmarshalled as a whole.
+func isNestedStruct(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+	ptr := reflect.PointerTo(typ)
+	return !ptr.Implements(excelUnmarshalerType) && !ptr.Implements(textUnmarshalerType)
+}
+
+// typeFields returns the fields of the struct type which bind to a column.
+// Only fields with the tag are bound, "-" skips a field.
+// Fields of embedded structs are promoted like encoding/json does it,
+// unless the embedded struct is tagged with a name.
+// Fields of nested structs are bound with the header "Nested.Header",
+// the prefix can be changed with the tag option "prefix", e.g. `excel:",prefix=Billing "`.
+// Further headers of a field can be listed with the tag option "alias",
+// e.g. `excel:"Name,alias=Full Name|名称"`.
+// If several fields bind to the same header,
+// the least nested one wins; on a tie none of them is bound.
+// Fields with an empty header are always bound, by position or column.
+func typeFields(typ reflect.Type, tagName string) []structField {
+	var fields []structField
+	var walk func(typ reflect.Type, index []int, name, prefix string, visited map[reflect.Type]bool)
+	walk = func(typ reflect.Type, index []int, name, prefix string, visited map[reflect.Type]bool) {
+		visited[typ] = true
+		defer delete(visited, typ)
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			tag, tagged := sf.Tag.Lookup(tagName)
+			if tag == "-" {
+				continue
+			}
+			ft := parseTag(tag)
+			fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+			ftyp := sf.Type
+			if ftyp.Kind() == reflect.Pointer {
+				ftyp = ftyp.Elem()
+			}
+			if isNestedStruct(sf.Type) {
+				if visited[ftyp] || (!sf.IsExported() && (!sf.Anonymous || sf.Type.Kind() == reflect.Pointer)) {
+					continue
+				}
+				nestedPrefix := prefix
+				if p, ok := ft.Options["prefix"]; ok {
+					nestedPrefix += p
+				} else if ft.Name != "" {
+					nestedPrefix += ft.Name + "."
+				} else if !sf.Anonymous {
+					nestedPrefix += sf.Name + "."
+				}
+				walk(ftyp, fieldIndex, name+sf.Name+".", nestedPrefix, visited)
+				continue
+			}
+			if !tagged || !sf.IsExported() {
+				continue
+			}
+			var aliases []string
+			if alias, ok := ft.Options["alias"]; ok {
+				for _, a := range strings.Split(alias, "|") {
+					aliases = append(aliases, prefix+a)
+				}
+			}
+			fields = append(fields, structField{
+				index:   fieldIndex,
+				name:    name + sf.Name,
+				header:  prefix + ft.Name,
+				aliases: aliases,
+				tag:     ft,
+				rawTag:  sf.Tag,
+				typ:     sf.Type,
+			})
+		}
+	}
+	walk(typ, nil, "", "", make(map[reflect.Type]bool))
+
+	// Resolve fields binding to the same header
+	byHeader := make(map[string][]int)
+	for i, f := range fields {
+		byHeader[f.header] = append(byHeader[f.header], i)
+	}
+	dominant := make([]structField, 0, len(fields))
+	for i, f := range fields {
+		candidates := byHeader[f.header]
+		if f.header == "" {
+			// Bound by position or column only
+			dominant = append(dominant, f)
+			continue
+		}
+		if candidates[0] != i {
+			continue
+		}
+		best, tie := i, false
+		for _, c := range candidates[1:] {
+			if d, bd := len(fields[c].index), len(fields[best].index); d < bd {
+				best, tie = c, false
+			} else if d == bd {
+				tie = true
+			}
+		}
+		if !tie {
+			dominant = append(dominant, fields[best])
+		}
+	}
+	return dominant
+}
+
+// column returns the 0-based column index configured with the tag option
+// "col" (column letters, e.g. `excel:"col=C"`) or "idx" (0-based index, e.g. `excel:"idx=2"`).
+func (sf structField) column() (int, bool, error) {
+	if letters, ok := sf.tag.Options["col"]; ok {
+		letters = strings.ToUpper(strings.TrimSpace(letters))
+		if letters == "" || strings.TrimLeft(letters, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return 0, false, fmt.Errorf("%w col=%s on field %s", ErrInvalidTag, letters, sf.name)
+		}
+		return xlsx.ColLettersToIndex(letters), true, nil
+	}
+	if idx, ok := sf.tag.Options["idx"]; ok {
+		columnIndex, err := strconv.Atoi(strings.TrimSpace(idx))
+		if err != nil || columnIndex < 0 {
+			return 0, false, fmt.Errorf("%w idx=%s on field %s", ErrInvalidTag, idx, sf.name)
+		}
+		return columnIndex, true, nil
+	}
+	return 0, false, nil
+}
+
+// fieldByIndex returns the nested field of the struct value,
+// allocating nil pointers to embedded or nested structs on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
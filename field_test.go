@@ -0,0 +1,87 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	equal(t, fieldTag{Name: "Name", Options: map[string]string{}}, parseTag("Name"))
+	equal(t, fieldTag{Name: "", Options: map[string]string{"prefix": ""}}, parseTag(",prefix="))
+	equal(t, fieldTag{Name: "Address", Options: map[string]string{"prefix": "Billing, "}}, parseTag("Address,prefix=Billing, "))
+	equal(t, fieldTag{Name: "Last, First", Options: map[string]string{}}, parseTag("Last, First"))
+	equal(t, fieldTag{Name: "", Options: map[string]string{"col": "C"}}, parseTag("col=C"))
+	equal(t, fieldTag{Name: "Name", Options: map[string]string{"idx": "4"}}, parseTag("Name,idx=4"))
+}
+
+type (
+	fieldsAudit struct {
+		CreatedBy string `excel:"Created By"`
+		UpdatedBy string `excel:"Updated By"`
+	}
+	fieldsAddress struct {
+		City string `excel:"City"`
+		Zip  string `excel:"Zip"`
+	}
+	fieldsTmp struct {
+		fieldsAudit
+		ID       int            `excel:"ID"`
+		Address  fieldsAddress  `excel:"Addr"`
+		Billing  *fieldsAddress `excel:",prefix=Billing "`
+		Shipping fieldsAddress
+		Inline   fieldsAddress `excel:",prefix="`
+		Ignored  fieldsAddress `excel:"-"`
+		Untagged string
+		Next     *fieldsTmp
+	}
+	fieldsConflict struct {
+		fieldsAudit
+		CreatedBy string `excel:"Created By"`
+		Left      struct {
+			Name string `excel:"Name"`
+		} `excel:",prefix="`
+		Right struct {
+			Name string `excel:"Name"`
+		} `excel:",prefix="`
+	}
+)
+
+func TestTypeFields(t *testing.T) {
+	headers := func(typ reflect.Type) (hs []string) {
+		for _, f := range typeFields(typ, "excel") {
+			hs = append(hs, f.header)
+		}
+		return
+	}
+	equal(t, []string{
+		"Created By", "Updated By", "ID",
+		"Addr.City", "Addr.Zip",
+		"Billing City", "Billing Zip",
+		"Shipping.City", "Shipping.Zip",
+		"City", "Zip",
+	}, headers(reflect.TypeOf(fieldsTmp{})))
+
+	// CreatedBy is less nested than the embedded one, Name is ambiguous
+	fields := typeFields(reflect.TypeOf(fieldsConflict{}), "excel")
+	equal(t, 2, len(fields))
+	equal(t, "Created By", fields[0].header)
+	equal(t, []int{1}, fields[0].index)
+	equal(t, "Updated By", fields[1].header)
+}
+
+func TestFieldByIndex(t *testing.T) {
+	v := reflect.New(reflect.TypeOf(fieldsTmp{})).Elem()
+	fieldByIndex(v, []int{3, 0}).SetString("Berlin")
+	equal(t, "Berlin", v.Interface().(fieldsTmp).Billing.City)
+}
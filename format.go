@@ -0,0 +1,77 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Format is a file format, e.g. from the format parameter of an export endpoint.
+type Format string
+
+const (
+	FormatXLSX Format = "xlsx"
+	FormatCSV  Format = "csv"
+	FormatTSV  Format = "tsv"
+	FormatODS  Format = "ods"
+)
+
+var ErrUnsupportedFormat = errors.New("exl: unsupported format")
+
+// ContentType returns the media type of the format, e.g. for the Content-Type header of a download,
+// "application/octet-stream" for unsupported formats.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatCSV:
+		return "text/csv"
+	case FormatTSV:
+		return "text/tab-separated-values"
+	case FormatODS:
+		return odsMimeType
+	}
+	return "application/octet-stream"
+}
+
+// ReadFormat reads a file in the format from the given io.ReaderAt,
+// see ReadReaderAt, ReadCSV and ReadODS.
+func ReadFormat[T any](reader io.ReaderAt, size int64, format Format, opts ...ReadOption) ([]T, error) {
+	switch format {
+	case FormatXLSX:
+		return ReadReaderAt[T](reader, size, opts...)
+	case FormatCSV:
+		return ReadCSV[T](io.NewSectionReader(reader, 0, size), opts...)
+	case FormatTSV:
+		return collectRows(rowsCSV[T](io.NewSectionReader(reader, 0, size), '\t', opts...))
+	case FormatODS:
+		return ReadODS[T](reader, size, opts...)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+}
+
+// WriteFormatTo writes the rows to w in the format, see WriteTo, WriteCSVTo and WriteODSTo.
+func WriteFormatTo[T WriteConfigurator](w io.Writer, format Format, ts []T) error {
+	switch format {
+	case FormatXLSX:
+		return WriteTo(w, ts)
+	case FormatCSV:
+		return WriteCSVTo(w, ts)
+	case FormatTSV:
+		return writeCSV(w, ts, '\t')
+	case FormatODS:
+		return WriteODSTo(w, ts)
+	}
+	return fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+}
@@ -0,0 +1,442 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	// columnTotals accumulates the numeric values of the columns for the totals row.
+	columnTotals struct {
+		label  string
+		funcs  []string // by column, "" for no total
+		styles []CellStyle
+		sums   []float64
+		counts []int
+		mins   []float64
+		maxs   []float64
+	}
+	// formulaParser computes the cached value of a formula of a row,
+	// see evalFormula.
+	formulaParser struct {
+		s     string
+		pos   int
+		row   int
+		value func(columnIndex int) (float64, bool)
+	}
+)
+
+var (
+	// totalFuncs are the functions of the tag option "total".
+	totalFuncs = map[string]bool{"SUM": true, "AVERAGE": true, "MIN": true, "MAX": true, "COUNT": true}
+	// formulaPlaceholderRegexp matches the placeholders of the tag option "formula", e.g. "{row}" or "{Price}".
+	formulaPlaceholderRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+	// cellRefRegexp matches the cell references and ranges of formulas, e.g. "A2" or "A2:A5".
+	cellRefRegexp = regexp.MustCompile(`\$?[A-Z]{1,3}\$?[0-9]+(?::\$?[A-Z]{1,3}\$?[0-9]+)?`)
+)
+
+// prepareFormulas replaces the header placeholders of the formulas of the columns,
+// e.g. "{Price}*{Qty}", with the cell references of the columns in the row, e.g. "C{row}*D{row}".
+func prepareFormulas(columns []writeColumn) error {
+	byHeader := make(map[string]int, len(columns))
+	for i, column := range columns {
+		byHeader[column.header] = i
+	}
+	for i, column := range columns {
+		if column.formula == "" {
+			continue
+		}
+		var err error
+		columns[i].formula = formulaPlaceholderRegexp.ReplaceAllStringFunc(column.formula, func(placeholder string) string {
+			name := placeholder[1 : len(placeholder)-1]
+			if name == "row" {
+				return placeholder
+			}
+			columnIndex, ok := byHeader[name]
+			if !ok {
+				err = fmt.Errorf("%w formula=%s on column %s, no column %s", ErrInvalidTag, column.formula, column.header, name)
+			}
+			return xlsx.ColIndexToLetters(columnIndex) + "{row}"
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// numericKind reports whether fields of the type are written as numbers by default.
+func numericKind(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	// Written by their marshal funcs, see GetMarshalFunc
+	if ptr := reflect.PointerTo(typ); ptr.Implements(reflect.TypeFor[ExcelMarshaler]()) ||
+		ptr.Implements(reflect.TypeFor[encoding.TextMarshaler]()) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setFormula writes the formula of the column for the 0-based row into the cell.
+// The cached value is computed from the numeric cells of the row, if possible,
+// otherwise it is the value of the field, if that is a number.
+// values returns the numeric value of a cell of the row by column.
+func setFormula(cell *Cell, column writeColumn, rowIndex int, field reflect.Value, values func(columnIndex int) (float64, bool)) error {
+	formula := strings.ReplaceAll(column.formula, "{row}", strconv.Itoa(rowIndex+1))
+	cached := ""
+	if v, ok := evalFormula(formula, rowIndex+1, values); ok {
+		cached = strconv.FormatFloat(v, 'f', -1, 64)
+	} else {
+		if err := marshalCell(cell, field); err != nil {
+			return err
+		}
+		if cell.Type() == CellTypeNumeric {
+			cached = cell.Value
+		}
+	}
+	cell.Value = cached
+	cell.SetFormula(formula)
+	return nil
+}
+
+// numericValue returns the number of a cell, if it holds one.
+func numericValue(cell *Cell) (float64, bool) {
+	if cell.Type() != CellTypeNumeric || cell.Value == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(cell.Value, 64)
+	return v, err == nil
+}
+
+// newColumnTotals returns the totals of the columns, nil without WriteConfig.TotalsRow.
+// Numeric columns are summed, unless the tag option "total" says otherwise.
+func newColumnTotals(columns []writeColumn, wc *WriteConfig) *columnTotals {
+	if !wc.TotalsRow {
+		return nil
+	}
+	n := len(columns)
+	ct := &columnTotals{
+		label:  wc.TotalsLabel,
+		funcs:  make([]string, n),
+		styles: make([]CellStyle, n),
+		sums:   make([]float64, n),
+		counts: make([]int, n),
+		mins:   make([]float64, n),
+		maxs:   make([]float64, n),
+	}
+	for i, column := range columns {
+		ct.funcs[i] = column.total
+		if column.total == "" && column.numeric {
+			ct.funcs[i] = "SUM"
+		} else if column.total == "-" {
+			ct.funcs[i] = ""
+		}
+		ct.styles[i] = column.style
+		ct.styles[i].Bold = true
+	}
+	return ct
+}
+
+// add accumulates the value of a cell of the column.
+func (ct *columnTotals) add(columnIndex int, cell *Cell) {
+	if ct == nil {
+		return
+	}
+	v, ok := numericValue(cell)
+	if !ok {
+		return
+	}
+	if ct.counts[columnIndex] == 0 || v < ct.mins[columnIndex] {
+		ct.mins[columnIndex] = v
+	}
+	if ct.counts[columnIndex] == 0 || v > ct.maxs[columnIndex] {
+		ct.maxs[columnIndex] = v
+	}
+	ct.sums[columnIndex] += v
+	ct.counts[columnIndex]++
+}
+
+// cell writes the total of the column over the 0-based rows from firstRow to lastRow into the cell.
+func (ct *columnTotals) cell(cell *Cell, columnIndex, firstRow, lastRow int) {
+	fn := ct.funcs[columnIndex]
+	if fn == "" {
+		if columnIndex == 0 {
+			cell.SetString(ct.label)
+		}
+		return
+	}
+	letters := xlsx.ColIndexToLetters(columnIndex)
+	cell.SetFormula(fmt.Sprintf("%s(%s%d:%s%d)", fn, letters, firstRow+1, letters, max(lastRow, firstRow)+1))
+	var cached float64
+	count := ct.counts[columnIndex]
+	switch fn {
+	case "SUM":
+		cached = ct.sums[columnIndex]
+	case "AVERAGE":
+		if count == 0 {
+			return
+		}
+		cached = ct.sums[columnIndex] / float64(count)
+	case "MIN":
+		cached = ct.mins[columnIndex]
+	case "MAX":
+		cached = ct.maxs[columnIndex]
+	case "COUNT":
+		cached = float64(count)
+	}
+	cell.Value = strconv.FormatFloat(cached, 'f', -1, 64)
+}
+
+// write adds the totals row for the data rows from firstRow up to the last row of the sheet.
+func (ct *columnTotals) write(sheet *xlsx.Sheet, firstRow int, styles styleCache, fit *autoFit) {
+	if ct == nil {
+		return
+	}
+	lastRow := sheet.MaxRow - 1
+	row := sheet.AddRow()
+	for columnIndex := range ct.funcs {
+		cell := &Cell{}
+		ct.cell(cell, columnIndex, firstRow, lastRow)
+		x := row.AddCell()
+		cell.copyTo(x)
+		styles.apply(x, ct.styles[columnIndex])
+		fit.add(x)
+	}
+}
+
+// evalFormula computes the value of a formula of the 1-based row:
+// numbers, references to cells of the row, + - * / ^, parentheses
+// and the functions SUM, AVERAGE, MIN, MAX, ABS and ROUND.
+// It reports false for anything else, to leave the computation to Excel.
+func evalFormula(formula string, row int, value func(columnIndex int) (float64, bool)) (float64, bool) {
+	p := &formulaParser{s: strings.TrimPrefix(formula, "="), row: row, value: value}
+	v, ok := p.expr()
+	p.space()
+	if !ok || p.pos < len(p.s) || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func (p *formulaParser) space() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// next consumes the character, if it is next.
+func (p *formulaParser) next(c byte) bool {
+	p.space()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *formulaParser) expr() (float64, bool) {
+	v, ok := p.term()
+	for ok {
+		if p.next('+') {
+			var w float64
+			w, ok = p.term()
+			v += w
+		} else if p.next('-') {
+			var w float64
+			w, ok = p.term()
+			v -= w
+		} else {
+			break
+		}
+	}
+	return v, ok
+}
+
+func (p *formulaParser) term() (float64, bool) {
+	v, ok := p.power()
+	for ok {
+		if p.next('*') {
+			var w float64
+			w, ok = p.power()
+			v *= w
+		} else if p.next('/') {
+			var w float64
+			w, ok = p.power()
+			if w == 0 {
+				return 0, false
+			}
+			v /= w
+		} else {
+			break
+		}
+	}
+	return v, ok
+}
+
+func (p *formulaParser) power() (float64, bool) {
+	v, ok := p.unary()
+	for ok && p.next('^') {
+		var w float64
+		w, ok = p.unary()
+		v = math.Pow(v, w)
+	}
+	return v, ok
+}
+
+func (p *formulaParser) unary() (float64, bool) {
+	if p.next('-') {
+		v, ok := p.unary()
+		return -v, ok
+	}
+	if p.next('+') {
+		return p.unary()
+	}
+	return p.primary()
+}
+
+func (p *formulaParser) primary() (float64, bool) {
+	p.space()
+	if p.next('(') {
+		v, ok := p.expr()
+		return v, ok && p.next(')')
+	}
+	start := p.pos
+	if p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.') {
+		for p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		return v, err == nil
+	}
+	for p.pos < len(p.s) && (p.s[p.pos] == '$' || p.s[p.pos] < unicode.MaxASCII && (unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos])))) {
+		p.pos++
+	}
+	name := strings.ToUpper(p.s[start:p.pos])
+	if name == "" {
+		return 0, false
+	}
+	if p.next('(') {
+		return p.call(name)
+	}
+	columnIndex, row, err := xlsx.GetCoordsFromCellIDString(strings.ReplaceAll(name, "$", ""))
+	if err != nil || row+1 != p.row {
+		return 0, false
+	}
+	return p.value(columnIndex)
+}
+
+// call computes a function, after its opening parenthesis.
+func (p *formulaParser) call(name string) (float64, bool) {
+	var args []float64
+	if !p.next(')') {
+		for {
+			v, ok := p.expr()
+			if !ok {
+				return 0, false
+			}
+			args = append(args, v)
+			if p.next(')') {
+				break
+			}
+			if !p.next(',') {
+				return 0, false
+			}
+		}
+	}
+	switch name {
+	case "SUM", "AVERAGE":
+		sum := 0.0
+		for _, v := range args {
+			sum += v
+		}
+		if name == "SUM" {
+			return sum, true
+		}
+		return sum / float64(len(args)), len(args) > 0
+	case "MIN", "MAX":
+		if len(args) == 0 {
+			return 0, true
+		}
+		v := args[0]
+		for _, w := range args[1:] {
+			if name == "MIN" {
+				v = min(v, w)
+			} else {
+				v = max(v, w)
+			}
+		}
+		return v, true
+	case "ABS":
+		if len(args) != 1 {
+			return 0, false
+		}
+		return math.Abs(args[0]), true
+	case "ROUND":
+		if len(args) != 2 {
+			return 0, false
+		}
+		scale := math.Pow(10, math.Trunc(args[1]))
+		// Excel rounds half away from zero
+		return math.Round(args[0]*scale) / scale, true
+	}
+	return 0, false
+}
+
+// replaceCellRefs applies the func to the cell references and ranges of the formula, e.g. "A2" or "$A$2:A5",
+// outside of string literals. References to other sheets, e.g. "Lists!A2", and names,
+// e.g. of the function LOG10, are kept.
+func replaceCellRefs(formula string, replace func(ref string) string) string {
+	return mapOutsideStrings(formula, func(s string) string {
+		var b strings.Builder
+		last := 0
+		for _, loc := range cellRefRegexp.FindAllStringIndex(s, -1) {
+			if loc[0] > 0 && isFormulaNameChar(s[loc[0]-1]) || loc[1] < len(s) && (isFormulaNameChar(s[loc[1]]) || s[loc[1]] == '(') {
+				continue
+			}
+			b.WriteString(s[last:loc[0]])
+			b.WriteString(replace(s[loc[0]:loc[1]]))
+			last = loc[1]
+		}
+		b.WriteString(s[last:])
+		return b.String()
+	})
+}
+
+func isFormulaNameChar(c byte) bool {
+	return c == '_' || c == '.' || c == '!' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// mapOutsideStrings applies the func to the parts of the formula outside of string literals.
+func mapOutsideStrings(formula string, replace func(s string) string) string {
+	parts := strings.Split(formula, `"`)
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = replace(parts[i])
+	}
+	return strings.Join(parts, `"`)
+}
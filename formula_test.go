@@ -0,0 +1,167 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	formulaTmp struct {
+		Name  string  `excel:"Name"`
+		Price float64 `excel:"Price,format=0.00"`
+		Qty   int     `excel:"Qty,total=AVERAGE"`
+		Total float64 `excel:"Total,formula={Price}*{Qty}"`
+		Upper string  `excel:"Upper,formula=UPPER(A{row})"`
+		Rank  int     `excel:"Rank,formula=RANK(C{row},C:C),total=-"`
+	}
+	formulaInvalidTmp struct {
+		Total float64 `excel:"Total,formula={Price}*2"`
+	}
+	totalInvalidTmp struct {
+		Total float64 `excel:"Total,total=MEDIAN"`
+	}
+	// formulaLevel is written as text, see MarshalText
+	formulaLevel int
+)
+
+func (l formulaLevel) MarshalText() ([]byte, error) { return []byte("L" + strconv.Itoa(int(l))), nil }
+
+func (*formulaTmp) WriteConfigure(wc *WriteConfig) {
+	wc.TotalsRow = true
+	wc.Table = true
+}
+
+func (*formulaInvalidTmp) WriteConfigure(_ *WriteConfig) {}
+func (*totalInvalidTmp) WriteConfigure(_ *WriteConfig)   {}
+
+var formulaTmpData = []*formulaTmp{
+	{Name: "apple", Price: 1.5, Qty: 2, Rank: 2},
+	{Name: "pear", Price: 0.25, Qty: 4, Rank: 1},
+}
+
+// checkFormulaSheet checks the rows written for formulaTmpData from the header row on.
+func checkFormulaSheet(t *testing.T, sheet *xlsx.Sheet, headerRow int) {
+	t.Helper()
+	cell := func(row, col int) *xlsx.Cell {
+		c, _ := sheet.Cell(headerRow+row, col)
+		return c
+	}
+	r := func(row int) string { return strconv.Itoa(headerRow + row + 1) }
+	equal(t, "B"+r(1)+"*C"+r(1), cell(1, 3).Formula())
+	equal(t, "3", cell(1, 3).Value)
+	equal(t, "1", cell(2, 3).Value)
+	equal(t, "UPPER(A"+r(2)+")", cell(2, 4).Formula())
+	equal(t, "", cell(2, 4).Value)
+	// Not computed, cached from the field
+	equal(t, "RANK(C"+r(2)+",C:C)", cell(2, 5).Formula())
+	equal(t, "1", cell(2, 5).Value)
+
+	equal(t, "Total", cell(3, 0).Value)
+	equal(t, "SUM(B"+r(1)+":B"+r(2)+")", cell(3, 1).Formula())
+	equal(t, "1.75", cell(3, 1).Value)
+	equal(t, "0.00", cell(3, 1).NumFmt)
+	equal(t, true, cell(3, 1).GetStyle().Font.Bold)
+	equal(t, "AVERAGE(C"+r(1)+":C"+r(2)+")", cell(3, 2).Formula())
+	equal(t, "3", cell(3, 2).Value)
+	equal(t, "SUM(D"+r(1)+":D"+r(2)+")", cell(3, 3).Formula())
+	equal(t, "4", cell(3, 3).Value)
+	equal(t, "", cell(3, 4).Formula())
+	equal(t, "", cell(3, 5).Formula())
+}
+
+func TestWriteFormula(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteTo(buf, formulaTmpData); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	checkFormulaSheet(t, f.Sheets[0], 0)
+	// The totals row is not part of the table
+	equal(t, true, strings.Contains(zipParts(t, buf.Bytes())["xl/tables/table1.xml"], `ref="A1:F3"`))
+
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	for _, err = range []error{
+		Write(testFile, []*formulaInvalidTmp{}),
+		Write(testFile, []*totalInvalidTmp{}),
+	} {
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Error("test failed: expected ErrInvalidTag, got:", err)
+		}
+	}
+}
+
+func TestWriterFormula(t *testing.T) {
+	w := NewWriter()
+	for i := 0; i < 2; i++ {
+		if err := w.Write("Orders", formulaTmpData); err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+	}
+	checkFormulaSheet(t, w.file.Sheet["Orders"], 0)
+	checkFormulaSheet(t, w.file.Sheet["Orders"], 4)
+}
+
+func TestWriteStreamFormula(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteStreamTo(buf, slices.Values(formulaTmpData)); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	checkFormulaSheet(t, f.Sheets[0], 0)
+	equal(t, true, strings.Contains(zipParts(t, buf.Bytes())["xl/tables/table1.xml"], `ref="A1:F3"`))
+}
+
+func TestEvalFormula(t *testing.T) {
+	values := func(columnIndex int) (float64, bool) { return float64(columnIndex + 1), columnIndex < 3 }
+	for formula, expected := range map[string]float64{
+		"A2*B2+C2":              5,
+		"=(A2+B2)*C2":           9,
+		"-A2^2":                 1,
+		"C2/B2":                 1.5,
+		"SUM(A2,B2,C2)/3":       2,
+		"ROUND(C2/7, 2)":        0.43,
+		"MAX($A$2,B2)-ABS(-C2)": -1,
+	} {
+		v, ok := evalFormula(formula, 2, values)
+		equal(t, true, ok)
+		equal(t, expected, v)
+	}
+	for _, formula := range []string{"A1*2", "D2", "A2/0", "SUM(A2:C2)", "TODAY()", "A2+", `"a"&"b"`} {
+		if _, ok := evalFormula(formula, 2, values); ok {
+			t.Errorf("test failed: expected %s not to be computed", formula)
+		}
+	}
+}
+
+func TestNumericKind(t *testing.T) {
+	equal(t, true, numericKind(reflect.TypeFor[*float64]()))
+	equal(t, false, numericKind(reflect.TypeFor[string]()))
+	// Numbers with a marshal func are not summed by default
+	equal(t, false, numericKind(reflect.TypeFor[formulaLevel]()))
+	equal(t, false, numericKind(reflect.TypeFor[writeStatus]()))
+}
@@ -4,7 +4,10 @@ go 1.24
 
 toolchain go1.24.6
 
-require codeberg.org/tealeg/xlsx/v4 v4.0.0
+require (
+	codeberg.org/tealeg/xlsx/v4 v4.0.0
+	golang.org/x/text v0.14.0
+)
 
 require (
 	github.com/frankban/quicktest v1.14.6 // indirect
@@ -16,5 +19,4 @@ require (
 	github.com/rogpeppe/fastuuid v1.2.0 // indirect
 	github.com/rogpeppe/go-internal v1.9.0 // indirect
 	github.com/shabbyrobe/xmlwriter v0.0.0-20230525083848-85336ec334fa // indirect
-	golang.org/x/text v0.14.0 // indirect
 )
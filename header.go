@@ -0,0 +1,72 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// HeaderNormalizerFunc normalizes a header before it is matched against the tags.
+// It is applied to the headers read from the sheet as well as to the tags,
+// see ReadConfig.HeaderNormalizer.
+type HeaderNormalizerFunc func(header string) string
+
+var (
+	// NormalizeCase folds the case of a header, so that "NAME" matches "name".
+	NormalizeCase HeaderNormalizerFunc = func(header string) string {
+		return cases.Fold().String(header)
+	}
+	// NormalizeSpace trims a header and collapses runs of white space into a single space.
+	NormalizeSpace HeaderNormalizerFunc = func(header string) string {
+		return strings.Join(strings.Fields(header), " ")
+	}
+	// NormalizeNewlines replaces line breaks in a header with a space,
+	// as they are often used to wrap long headers in a cell.
+	NormalizeNewlines HeaderNormalizerFunc = func(header string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '\n' || r == '\r' || r == '\v' || r == '\f' || r == '\u0085' || r == '\u2028' || r == '\u2029' {
+				return ' '
+			}
+			return r
+		}, header)
+	}
+	// NormalizeNFKC applies Unicode NFKC normalization to a header,
+	// which e.g. maps full-width characters to their half-width form.
+	NormalizeNFKC HeaderNormalizerFunc = func(header string) string {
+		return norm.NFKC.String(header)
+	}
+	// NormalizeAll combines all built-in normalizers.
+	NormalizeAll = NormalizeHeaders(NormalizeNFKC, NormalizeNewlines, NormalizeSpace, NormalizeCase)
+)
+
+// NormalizeHeaders combines several normalizers, which are applied in the given order.
+func NormalizeHeaders(normalizers ...HeaderNormalizerFunc) HeaderNormalizerFunc {
+	return func(header string) string {
+		for _, normalizer := range normalizers {
+			if normalizer != nil {
+				header = normalizer(header)
+			}
+		}
+		return header
+	}
+}
+
+// normalizeHeader applies the normalizer, if any.
+func normalizeHeader(normalizer HeaderNormalizerFunc, header string) string {
+	if normalizer == nil {
+		return header
+	}
+	return normalizer(header)
+}
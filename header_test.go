@@ -0,0 +1,60 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"path"
+	"testing"
+)
+
+func TestNormalizers(t *testing.T) {
+	equal(t, "full name", NormalizeCase("Full NAME"))
+	equal(t, "Full Name", NormalizeSpace("  Full \t  Name "))
+	equal(t, "Full  Name", NormalizeNewlines("Full\r\nName"))
+	equal(t, "Name1", NormalizeNFKC("Ｎａｍｅ１"))
+	equal(t, "unit price", NormalizeAll(" Ｕｎｉｔ\nPRICE "))
+	equal(t, "Name", NormalizeHeaders()("Name"))
+	equal(t, "Name", normalizeHeader(nil, "Name"))
+}
+
+type readAliasTmp struct {
+	Name  string `excel:"Name,alias=Full Name|名称"`
+	Price int    `excel:"Unit Price"`
+	Other string `excel:"Other,alias=Name"`
+}
+
+func TestReadHeaderAliases(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"名称", "UNIT\nprice", "Other"},
+		{"Apple", "3", "x"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("without normalizer", func(t *testing.T) {
+		models, err := ReadFile[*readAliasTmp](testFile)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readAliasTmp{{Name: "Apple", Other: "x"}}, models)
+	})
+	t.Run("with normalizer", func(t *testing.T) {
+		models, err := ReadFile[*readAliasTmp](testFile, WithHeaderNormalizer(NormalizeAll))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readAliasTmp{{Name: "Apple", Price: 3, Other: "x"}}, models)
+	})
+}
@@ -0,0 +1,321 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// InstructionsSheetName is the name of the sheet describing the columns of an import template.
+const InstructionsSheetName = "Instructions"
+
+var (
+	// requiredHeaderColor is the font color of the headers of required columns in import templates.
+	requiredHeaderColor = "FFC00000"
+	// instructionsHeaders are the headers of the instructions sheet.
+	instructionsHeaders = []string{"Column", "Type", "Required", "Allowed values", "Rules", "Description"}
+)
+
+// importColumn describes a column of an import template.
+type importColumn struct {
+	writeColumn
+	typ         string
+	required    bool
+	allowed     []string
+	rules       []string
+	description string
+	examples    []string
+}
+
+// WriteImportTemplate writes a blank import template for T to the excel file:
+// the styled header row with the description of the column as comment,
+// dropdowns for enums, example rows and a sheet "Instructions"
+// listing the type, whether it is required and the allowed values of every column.
+//
+// Descriptions and examples are taken from the tags
+// WriteConfig.DescriptionTagName and WriteConfig.ExampleTagName, e.g.
+//
+//	Status string `excel:"Status,enum=Active|Closed" desc:"State of the account" example:"Active"`
+//
+// Required columns, with the tag option "required" or the validation rule "required",
+// have a red header. Without WriteConfig.HeaderStyle, headers are bold.
+// If T implements ReadConfigurator, its ValidateTagName is used.
+func WriteImportTemplate[T WriteConfigurator](file string) error {
+	f, extend, err := importTemplate[T]()
+	if err != nil {
+		return err
+	}
+	return savePackage(f, file, extend)
+}
+
+// WriteImportTemplateTo writes a blank import template for T to w, see WriteImportTemplate.
+func WriteImportTemplateTo[T WriteConfigurator](w io.Writer) error {
+	f, extend, err := importTemplate[T]()
+	if err != nil {
+		return err
+	}
+	return writePackage(f, w, extend)
+}
+
+// importTemplate returns the template and the func adding the header comments.
+func importTemplate[T WriteConfigurator]() (*xlsx.File, func(p *xlsxPackage) error, error) {
+	wc := defaultWriteConfig()
+	tT := new(T)
+	(*tT).WriteConfigure(wc)
+	rc := defaultReadConfig()
+	if configurator, ok := any(*tT).(ReadConfigurator); ok {
+		configurator.ReadConfigure(rc)
+	}
+	typ := reflect.TypeOf(tT).Elem().Elem()
+	columns, err := importColumns(typ, wc, rc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := xlsx.NewFile()
+	sheet, err := f.AddSheet(wc.SheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+	styles := styleCache{}
+	fit := newAutoFit(wc.MaxAutoFitWidth)
+	headerStyle := wc.HeaderStyle
+	if headerStyle == (CellStyle{}) {
+		headerStyle.Bold = true
+	}
+	var comments []cellComment
+	header := sheet.AddRow()
+	for columnIndex, column := range columns {
+		cell := header.AddCell()
+		cell.SetString(column.header)
+		cs := headerStyle
+		if column.required {
+			cs.FontColor = requiredHeaderColor
+		}
+		styles.apply(cell, cs)
+		fit.add(cell)
+		if column.width > 0 {
+			sheet.SetColWidth(columnIndex+1, columnIndex+1, column.width)
+			fit.fix(columnIndex)
+		}
+		if column.description != "" {
+			comments = append(comments, cellComment{row: 0, col: columnIndex, text: column.description})
+		}
+	}
+	for rowIndex := 0; ; rowIndex++ {
+		var row *xlsx.Row
+		for columnIndex, column := range columns {
+			if rowIndex >= len(column.examples) {
+				continue
+			}
+			if row == nil {
+				row = sheet.AddRow()
+			}
+			cell := row.GetCell(columnIndex)
+			if err = exampleCell(cell, typ, column, column.examples[rowIndex], rc); err != nil {
+				return nil, nil, err
+			}
+			styles.apply(cell, column.style)
+			fit.add(cell)
+		}
+		if row == nil {
+			break
+		}
+	}
+	fit.apply(sheet)
+	sheet.SheetViews = []xlsx.SheetView{{Pane: &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"}}}
+
+	if err = writeInstructions(f, columns, headerStyle); err != nil {
+		return nil, nil, err
+	}
+	dropdownColumns := make([]writeColumn, len(columns))
+	for i, column := range columns {
+		dropdownColumns[i] = column.writeColumn
+	}
+	if err = addDropdowns(f, sheet, dropdownColumns, 0); err != nil {
+		return nil, nil, err
+	}
+	return f, func(p *xlsxPackage) error { return p.addComments(0, comments) }, nil
+}
+
+// importColumns describes the columns of the struct type as read back,
+// with the fields, headers and prefixes ReadConfig.TagName binds.
+// Fields bound to a column with the tag option "col" or "idx" are moved to it,
+// after blank columns if the other fields do not reach it.
+func importColumns(typ reflect.Type, wc *WriteConfig, rc *ReadConfig) ([]importColumn, error) {
+	type placedColumn struct {
+		columnIndex int
+		column      importColumn
+	}
+	var ics []importColumn
+	var placed []placedColumn
+	for _, sf := range typeFields(typ, rc.TagName) {
+		column, err := tagColumn(sf.tag, sf.index, sf.name, sf.typ)
+		if err != nil {
+			return nil, err
+		}
+		columnIndex, bound, err := sf.column()
+		if err != nil {
+			return nil, err
+		}
+		if column.header = sf.header; column.header == "" {
+			// Bound by position or column, like the missing headers of a HeaderError
+			column.header = sf.name
+			if bound {
+				column.header = xlsx.ColIndexToLetters(columnIndex)
+			}
+		}
+		ic := importColumn{
+			writeColumn: column,
+			typ:         typeDescription(sf.typ, column.style.Format),
+			required:    sf.tag.Has("required"),
+			allowed:     column.enum,
+			description: sf.rawTag.Get(wc.DescriptionTagName),
+		}
+		if example := sf.rawTag.Get(wc.ExampleTagName); example != "" {
+			ic.examples = strings.Split(example, "|")
+		}
+		for _, rule := range splitValidateTag(sf.rawTag.Get(rc.ValidateTagName)) {
+			switch rule.Rule {
+			case "required":
+				ic.required = true
+			case "oneof":
+				if ic.allowed == nil {
+					ic.allowed = strings.Split(rule.Param, "|")
+				}
+			default:
+				ic.rules = append(ic.rules, rule.Error())
+			}
+		}
+		if bound {
+			placed = append(placed, placedColumn{columnIndex, ic})
+			continue
+		}
+		ics = append(ics, ic)
+	}
+	sort.SliceStable(placed, func(i, j int) bool { return placed[i].columnIndex < placed[j].columnIndex })
+	for _, p := range placed {
+		for len(ics) < p.columnIndex {
+			// Blank column before the bound one
+			ics = append(ics, importColumn{})
+		}
+		ics = slices.Insert(ics, p.columnIndex, p.column)
+	}
+	return ics, nil
+}
+
+// typeDescription describes the type of a column for the instructions sheet.
+func typeDescription(typ reflect.Type, format string) string {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	description := typ.String()
+	switch {
+	case typ == timeType:
+		description = "Date"
+	case reflect.PointerTo(typ).Implements(reflect.TypeFor[ExcelMarshaler]()),
+		reflect.PointerTo(typ).Implements(reflect.TypeFor[encoding.TextMarshaler]()):
+		description = "Text"
+	default:
+		switch typ.Kind() {
+		case reflect.String:
+			description = "Text"
+		case reflect.Bool:
+			description = "TRUE/FALSE"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			description = "Integer"
+		case reflect.Float32, reflect.Float64:
+			description = "Number"
+		}
+	}
+	if format != "" {
+		description += " (" + format + ")"
+	}
+	return description
+}
+
+// exampleCell writes an example value into the cell,
+// read into the field like an imported cell and written like Write does,
+// so numbers and dates are not written as text.
+func exampleCell(cell *xlsx.Cell, typ reflect.Type, column importColumn, example string, rc *ReadConfig) error {
+	if example == "" {
+		return nil
+	}
+	value := reflect.New(typ).Elem()
+	field := fieldByIndex(value, column.index)
+	src := &Cell{}
+	src.SetString(example)
+	unmarshal := rc.unmarshalFunc(field, column.header)
+	if unmarshal == nil {
+		cell.SetString(example)
+		return nil
+	}
+	params := &ExcelUnmarshalParameters{
+		TrimSpace:           rc.TrimSpace,
+		FallbackDateFormats: slices.Concat(rc.FallbackDateFormats, []string{"2006-01-02", "2006-01-02 15:04:05"}),
+	}
+	if err := unmarshal(field, src, params); err != nil {
+		return fmt.Errorf("%w example %q of column \"%s\": %w", ErrInvalidTag, example, column.header, err)
+	}
+	return marshalXLSXCell(cell, field)
+}
+
+// writeInstructions adds the sheet describing the columns.
+func writeInstructions(f *xlsx.File, columns []importColumn, headerStyle CellStyle) error {
+	sheet, err := f.AddSheet(InstructionsSheetName)
+	if err != nil {
+		return err
+	}
+	styles := styleCache{}
+	fit := newAutoFit(60)
+	header := sheet.AddRow()
+	for _, h := range instructionsHeaders {
+		cell := header.AddCell()
+		cell.SetString(h)
+		styles.apply(cell, headerStyle)
+		fit.add(cell)
+	}
+	for _, column := range columns {
+		if column.index == nil {
+			continue
+		}
+		required := "No"
+		if column.required {
+			required = "Yes"
+		}
+		row := sheet.AddRow()
+		for _, text := range []string{
+			column.header,
+			column.typ,
+			required,
+			strings.Join(column.allowed, ", "),
+			strings.Join(column.rules, "; "),
+			column.description,
+		} {
+			cell := row.AddCell()
+			cell.SetString(text)
+			styles.apply(cell, CellStyle{Wrap: true})
+			fit.add(cell)
+		}
+	}
+	fit.apply(sheet)
+	return nil
+}
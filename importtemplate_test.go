@@ -0,0 +1,163 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	importTemplateTmp struct {
+		ID     int       `excel:"ID,required" desc:"Account number" example:"1001|1002"`
+		Status string    `excel:"Status,enum=Active|Closed" desc:"State of the account" example:"Active"`
+		Joined time.Time `excel:"Joined,format=yyyy-mm-dd" example:"2024-01-31"`
+		Score  float64   `excel:"Score" validate:"required,min=0,max=100"`
+		Grade  string    `excel:"Grade" validate:"oneof=A|B|C"`
+	}
+	importTemplateInvalidTmp struct {
+		ID int `excel:"ID" example:"one"`
+	}
+	importTemplateBaseTmp struct {
+		ID int `excel:"ID,required"`
+	}
+	importTemplateAddressTmp struct {
+		City string `excel:"City"`
+	}
+	importTemplateNestedTmp struct {
+		importTemplateBaseTmp
+		Name    string                   `excel:"Name"`
+		Address importTemplateAddressTmp `excel:",prefix=Billing "`
+		Code    string                   `excel:"col=D"`
+		Note    string
+	}
+)
+
+func (*importTemplateTmp) WriteConfigure(wc *WriteConfig) { wc.SheetName = "Accounts" }
+
+func (*importTemplateInvalidTmp) WriteConfigure(_ *WriteConfig) {}
+func (*importTemplateNestedTmp) WriteConfigure(_ *WriteConfig)  {}
+func (*importTemplateNestedTmp) ReadConfigure(_ *ReadConfig)    {}
+
+func TestWriteImportTemplate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteImportTemplateTo[*importTemplateTmp](buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheets[0]
+	equal(t, "Accounts", sheet.Name)
+	equal(t, 3, sheet.MaxRow)
+	cell := func(sheet *xlsx.Sheet, row, col int) *xlsx.Cell {
+		c, _ := sheet.Cell(row, col)
+		return c
+	}
+	for i, header := range []string{"ID", "Status", "Joined", "Score", "Grade"} {
+		equal(t, header, cell(sheet, 0, i).Value)
+		equal(t, true, cell(sheet, 0, i).GetStyle().Font.Bold)
+	}
+	equal(t, requiredHeaderColor, cell(sheet, 0, 0).GetStyle().Font.Color)
+	equal(t, requiredHeaderColor, cell(sheet, 0, 3).GetStyle().Font.Color)
+	equal(t, "", cell(sheet, 0, 1).GetStyle().Font.Color)
+
+	// Example rows
+	id, _ := cell(sheet, 1, 0).Int()
+	equal(t, 1001, id)
+	id, _ = cell(sheet, 2, 0).Int()
+	equal(t, 1002, id)
+	equal(t, "Active", cell(sheet, 1, 1).Value)
+	equal(t, "", cell(sheet, 2, 1).Value)
+	joined, _ := cell(sheet, 1, 2).GetTime(false)
+	equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), joined)
+	equal(t, "yyyy-mm-dd", cell(sheet, 1, 2).NumFmt)
+	equal(t, "", cell(sheet, 1, 3).Value)
+
+	equal(t, 1, len(sheet.DataValidations))
+	equal(t, "B2:B1048576", sheet.DataValidations[0].Sqref)
+
+	parts := zipParts(t, buf.Bytes())
+	equal(t, true, strings.Contains(parts["xl/comments1.xml"], "Account number"))
+	equal(t, true, strings.Contains(parts["xl/comments1.xml"], "State of the account"))
+
+	instructions := f.Sheet[InstructionsSheetName]
+	equal(t, 6, instructions.MaxRow)
+	rows := make([][]string, 0, instructions.MaxRow)
+	for i := 0; i < instructions.MaxRow; i++ {
+		row := make([]string, len(instructionsHeaders))
+		for j := range row {
+			row[j] = cell(instructions, i, j).Value
+		}
+		rows = append(rows, row)
+	}
+	equal(t, [][]string{
+		instructionsHeaders,
+		{"ID", "Integer", "Yes", "", "", "Account number"},
+		{"Status", "Text", "No", "Active, Closed", "", "State of the account"},
+		{"Joined", "Date (yyyy-mm-dd)", "No", "", "", ""},
+		{"Score", "Number", "Yes", "", "value must be at least 0; value must be at most 100", ""},
+		{"Grade", "Text", "No", "A, B, C", "", ""},
+	}, rows)
+}
+
+func TestWriteImportTemplateInvalidExample(t *testing.T) {
+	if err := WriteImportTemplateTo[*importTemplateInvalidTmp](&bytes.Buffer{}); !errors.Is(err, ErrInvalidTag) {
+		t.Error("test failed: expected ErrInvalidTag, got:", err)
+	}
+}
+
+func TestImportTemplateRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteImportTemplateTo[*importTemplateNestedTmp](buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	// The columns the reader binds, the untagged field is not read
+	sheet := f.Sheets[0]
+	var headers []string
+	for i := 0; i < sheet.MaxCol; i++ {
+		c, _ := sheet.Cell(0, i)
+		headers = append(headers, c.Value)
+	}
+	equal(t, []string{"ID", "Name", "Billing City", "D"}, headers)
+
+	row := sheet.AddRow()
+	row.AddCell().SetInt(7)
+	row.AddCell().SetString("Coco")
+	row.AddCell().SetString("Paris")
+	row.AddCell().SetString("X1")
+	out := &bytes.Buffer{}
+	if err = f.Write(out); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	models, err := ReadBinary[*importTemplateNestedTmp](out.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 1, len(models))
+	equal(t, importTemplateNestedTmp{
+		importTemplateBaseTmp: importTemplateBaseTmp{ID: 7},
+		Name:                  "Coco",
+		Address:               importTemplateAddressTmp{City: "Paris"},
+		Code:                  "X1",
+	}, *models[0])
+}
@@ -0,0 +1,685 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+const (
+	odsMimeType        = "application/vnd.oasis.opendocument.spreadsheet"
+	odsOfficeNamespace = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsTableNamespace  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsTextNamespace   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+var (
+	// odsRefRegexp matches the cell references and ranges of ODS formulas, e.g. "[.A2]" or "[.A2:.A5]".
+	odsRefRegexp = regexp.MustCompile(`\[\.(\$?[A-Z]+\$?[0-9]+)(?::\.(\$?[A-Z]+\$?[0-9]+))?\]`)
+	// odsTimeRegexp matches the time values of ODS cells, e.g. "PT12H30M00S".
+	odsTimeRegexp = regexp.MustCompile(`^PT(\d+)H(\d+)M(\d+(?:\.\d+)?)S$`)
+	// numberFormatRegexp matches the number formats written as ODS number styles, e.g. "#,##0.00" or "0%".
+	numberFormatRegexp = regexp.MustCompile(`^(#,##)?0(?:\.(0+))?(%)?$`)
+)
+
+type (
+	// odsWorkbook is an OpenDocument spreadsheet, whose tables are read from content.xml.
+	odsWorkbook struct {
+		zr    *zip.Reader
+		names []string
+	}
+	// odsSheet is a table of an odsWorkbook, read into memory.
+	// Repeated rows and cells are expanded, unless they are empty and last.
+	odsSheet struct {
+		cells  [][]*Cell
+		maxCol int
+	}
+	// odsStyles collects the distinct cell styles, written as automatic styles.
+	odsStyles struct {
+		names map[odsStyleKey]string
+		keys  []odsStyleKey
+	}
+	odsStyleKey struct {
+		bold   bool
+		numFmt string
+	}
+)
+
+// ReadODS reads an OpenDocument spreadsheet (.ods) from the given io.ReaderAt.
+// Each row is parsed and unmarshalled into a slice of `T`, like ReadParsed,
+// with the same tags, ReadConfig and unmarshal funcs.
+// Dates and times are read as Excel serial numbers with a date format, like in xlsx files.
+func ReadODS[T any](reader io.ReaderAt, size int64, opts ...ReadOption) ([]T, error) {
+	return collectRows(RowsODS[T](reader, size, opts...))
+}
+
+// ReadODSFile reads an OpenDocument spreadsheet at the given file path, see ReadODS.
+func ReadODSFile[T any](file string, opts ...ReadOption) ([]T, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ReadODS[T](f, info.Size(), opts...)
+}
+
+// RowsODS returns an iterator over the rows of an OpenDocument spreadsheet read from the given io.ReaderAt,
+// see ReadODS and Rows. The file is read when the iteration starts.
+func RowsODS[T any](reader io.ReaderAt, size int64, opts ...ReadOption) iter.Seq2[T, error] {
+	return readSheet[T](context.Background(), func(*ReadConfig) (workbookBackend, error) {
+		return openODSWorkbook(reader, size)
+	}, opts...)
+}
+
+// openODSWorkbook checks the mime type and reads the names of the tables.
+func openODSWorkbook(reader io.ReaderAt, size int64) (*odsWorkbook, error) {
+	zr, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	wb := &odsWorkbook{zr: zr}
+	mimeType, err := wb.read("mimetype")
+	if err != nil || string(mimeType) != odsMimeType {
+		return nil, fmt.Errorf("%w: not an OpenDocument spreadsheet", ErrInvalidWorkbook)
+	}
+	err = wb.tables(func(d *xml.Decoder, start xml.StartElement) (bool, error) {
+		wb.names = append(wb.names, odsAttr(start, odsTableNamespace, "name"))
+		return true, d.Skip()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wb, nil
+}
+
+// read returns the content of the part of the zip archive.
+func (wb *odsWorkbook) read(part string) ([]byte, error) {
+	r, err := wb.zr.Open(part)
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing part %s", ErrInvalidWorkbook, part)
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+// tables calls the func for every table of content.xml, until it returns false.
+// The func reads the table up to its end element.
+func (wb *odsWorkbook) tables(f func(d *xml.Decoder, start xml.StartElement) (bool, error)) error {
+	r, err := wb.zr.Open("content.xml")
+	if err != nil {
+		return fmt.Errorf("%w: missing part content.xml", ErrInvalidWorkbook)
+	}
+	defer func() { _ = r.Close() }()
+	d := xml.NewDecoder(r)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Space == odsTableNamespace && start.Name.Local == "table" {
+			if next, err := f(d, start); err != nil || !next {
+				return err
+			}
+		}
+	}
+}
+
+func (wb *odsWorkbook) sheetNames() []string { return wb.names }
+
+func (wb *odsWorkbook) sheet(index int) (sheetBackend, error) {
+	var s *odsSheet
+	tableIndex := 0
+	err := wb.tables(func(d *xml.Decoder, _ xml.StartElement) (bool, error) {
+		if tableIndex < index {
+			tableIndex++
+			return true, d.Skip()
+		}
+		var err error
+		s, err = readODSTable(d)
+		return false, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *odsSheet) dimension() (maxRow, maxCol int, err error) { return len(s.cells), s.maxCol, nil }
+
+func (s *odsSheet) date1904() bool { return false }
+
+func (s *odsSheet) rows() iter.Seq2[sheetRow, error] {
+	return func(yield func(sheetRow, error) bool) {
+		for rowIndex, cells := range s.cells {
+			if !yield(sheetRow{index: rowIndex, cells: cells}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readODSTable reads the rows of a table up to its end element.
+func readODSTable(d *xml.Decoder) (*odsSheet, error) {
+	s := &odsSheet{}
+	emptyRows := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTableNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "table-row":
+				cells, err := readODSRow(d)
+				if err != nil {
+					return nil, err
+				}
+				repeat := odsRepeat(t, "number-rows-repeated")
+				if len(cells) == 0 {
+					emptyRows += repeat
+					continue
+				}
+				for ; emptyRows > 0; emptyRows-- {
+					s.cells = append(s.cells, nil)
+				}
+				for range repeat {
+					s.cells = append(s.cells, cells)
+				}
+				s.maxCol = max(s.maxCol, len(cells))
+			}
+		case xml.EndElement:
+			if t.Name.Space == odsTableNamespace && t.Name.Local == "table" {
+				return s, nil
+			}
+		}
+	}
+}
+
+// readODSRow reads the cells of a row up to its end element.
+func readODSRow(d *xml.Decoder) ([]*Cell, error) {
+	var cells []*Cell
+	emptyCells := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTableNamespace || (t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell") {
+				if err = d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			cell, err := readODSCell(d, t)
+			if err != nil {
+				return nil, err
+			}
+			repeat := odsRepeat(t, "number-columns-repeated")
+			if cell == nil {
+				emptyCells += repeat
+				continue
+			}
+			for ; emptyCells > 0; emptyCells-- {
+				cells = append(cells, nil)
+			}
+			for range repeat {
+				cells = append(cells, cell)
+			}
+		case xml.EndElement:
+			return cells, nil
+		}
+	}
+}
+
+// readODSCell reads a cell up to its end element, nil if it is empty.
+// Numbers, dates, times and booleans are read like xlsx cells.
+func readODSCell(d *xml.Decoder, start xml.StartElement) (*Cell, error) {
+	text, err := readODSText(d)
+	if err != nil {
+		return nil, err
+	}
+	cell := &Cell{Value: text, NumFmt: "general"}
+	if formula := odsAttr(start, odsTableNamespace, "formula"); formula != "" {
+		cell.formula = fromODSFormula(formula)
+	}
+	switch valueType := odsAttr(start, odsOfficeNamespace, "value-type"); valueType {
+	case "float", "percentage", "currency":
+		cell.Value = odsAttr(start, odsOfficeNamespace, "value")
+		cell.cellType = CellTypeNumeric
+	case "date":
+		value := odsAttr(start, odsOfficeNamespace, "date-value")
+		for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				cell.Value = strconv.FormatFloat(xlsx.TimeToExcelTime(t, false), 'f', -1, 64)
+				cell.cellType = CellTypeNumeric
+				cell.NumFmt = "yyyy-mm-dd"
+				if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+					cell.NumFmt = "yyyy-mm-dd hh:mm:ss"
+				}
+				break
+			}
+		}
+	case "time":
+		if match := odsTimeRegexp.FindStringSubmatch(odsAttr(start, odsOfficeNamespace, "time-value")); match != nil {
+			hours, _ := strconv.ParseFloat(match[1], 64)
+			minutes, _ := strconv.ParseFloat(match[2], 64)
+			seconds, _ := strconv.ParseFloat(match[3], 64)
+			cell.Value = strconv.FormatFloat((hours*3600+minutes*60+seconds)/86400, 'f', -1, 64)
+			cell.cellType = CellTypeNumeric
+			cell.NumFmt = "hh:mm:ss"
+		}
+	case "boolean":
+		cell.Value = "0"
+		if odsAttr(start, odsOfficeNamespace, "boolean-value") == "true" {
+			cell.Value = "1"
+		}
+		cell.cellType = CellTypeBool
+	case "string":
+		if value := odsAttr(start, odsOfficeNamespace, "string-value"); value != "" {
+			cell.Value = value
+		}
+	case "":
+		if cell.Value == "" && cell.formula == "" {
+			return nil, nil
+		}
+	}
+	return cell, nil
+}
+
+// readODSText returns the text of the paragraphs of a cell, one per line,
+// reading up to the end element of the cell. Comments are not read.
+func readODSText(d *xml.Decoder) (string, error) {
+	var b strings.Builder
+	depth, paragraphs, inParagraph, annotation := 0, 0, 0, 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case t.Name.Space == odsOfficeNamespace && t.Name.Local == "annotation":
+				annotation++
+			case annotation > 0 || t.Name.Space != odsTextNamespace:
+			case t.Name.Local == "p" || t.Name.Local == "h":
+				if paragraphs > 0 {
+					b.WriteByte('\n')
+				}
+				paragraphs++
+				inParagraph++
+			case t.Name.Local == "s":
+				count, err := strconv.Atoi(odsAttr(t, odsTextNamespace, "c"))
+				if err != nil || count < 1 {
+					count = 1
+				}
+				b.WriteString(strings.Repeat(" ", count))
+			case t.Name.Local == "tab":
+				b.WriteByte('\t')
+			case t.Name.Local == "line-break":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				return b.String(), nil
+			}
+			depth--
+			switch {
+			case t.Name.Space == odsOfficeNamespace && t.Name.Local == "annotation":
+				annotation--
+			case annotation == 0 && t.Name.Space == odsTextNamespace && (t.Name.Local == "p" || t.Name.Local == "h"):
+				inParagraph--
+			}
+		case xml.CharData:
+			if annotation == 0 && inParagraph > 0 {
+				b.Write(t)
+			}
+		}
+	}
+}
+
+// odsAttr returns the value of the attribute in the namespace, "" if missing.
+func odsAttr(start xml.StartElement, space, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Space == space && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// odsRepeat returns the number of repetitions of a row or cell, at least 1.
+func odsRepeat(start xml.StartElement, name string) int {
+	if repeat, err := strconv.Atoi(odsAttr(start, odsTableNamespace, name)); err == nil && repeat > 1 {
+		return repeat
+	}
+	return 1
+}
+
+// fromODSFormula returns the Excel formula of an ODS formula,
+// e.g. "A2*B2" for "of:=[.A2]*[.B2]".
+func fromODSFormula(formula string) string {
+	if i := strings.IndexByte(formula, ':'); i >= 0 && i < strings.IndexByte(formula, '=') {
+		// Namespace prefix
+		formula = formula[i+1:]
+	}
+	return mapOutsideStrings(strings.TrimPrefix(formula, "="), func(s string) string {
+		s = odsRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+			match := odsRefRegexp.FindStringSubmatch(ref)
+			if match[2] == "" {
+				return match[1]
+			}
+			return match[1] + ":" + match[2]
+		})
+		return strings.ReplaceAll(s, ";", ",")
+	})
+}
+
+// toODSFormula returns the ODS formula of an Excel formula,
+// e.g. "of:=[.A2]*[.B2]" for "A2*B2".
+func toODSFormula(formula string) string {
+	formula = replaceCellRefs(formula, func(ref string) string {
+		return "[." + strings.Replace(ref, ":", ":.", 1) + "]"
+	})
+	return "of:=" + mapOutsideStrings(formula, func(s string) string { return strings.ReplaceAll(s, ",", ";") })
+}
+
+// WriteODS writes the rows to the OpenDocument spreadsheet (.ods) file,
+// with the same tags and WriteConfig as Write.
+// The number formats, the bold header style and the totals row are written,
+// other styles, widths, dropdowns, tables and frozen headers are not.
+func WriteODS[T WriteConfigurator](file string, ts []T) error {
+	target, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = WriteODSTo(target, ts); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// WriteODSTo writes the rows to w as OpenDocument spreadsheet, see WriteODS.
+func WriteODSTo[T WriteConfigurator](w io.Writer, ts []T) error {
+	wc := defaultWriteConfig()
+	tT := new(T)
+	(*tT).WriteConfigure(wc)
+	columns, err := writeColumns(reflect.TypeOf(tT).Elem().Elem(), wc)
+	if err != nil {
+		return err
+	}
+	styles := &odsStyles{names: make(map[odsStyleKey]string)}
+	body := &bytes.Buffer{}
+	fmt.Fprintf(body, `<table:table table:name="%s"><table:table-column table:number-columns-repeated="%d"/>`, xmlAttr(wc.SheetName), max(len(columns), 1))
+	body.WriteString(`<table:table-row>`)
+	for _, column := range columns {
+		cell := &Cell{}
+		cell.SetString(column.header)
+		writeODSCell(body, cell, styles.name(wc.HeaderStyle.Bold, ""))
+	}
+	body.WriteString(`</table:table-row>`)
+	totals := newColumnTotals(columns, wc)
+	for i, t := range ts {
+		cells, err := marshalRow(columns, i+1, reflect.ValueOf(t).Elem())
+		if err != nil {
+			return err
+		}
+		body.WriteString(`<table:table-row>`)
+		for columnIndex, cell := range cells {
+			if format := columns[columnIndex].style.Format; format != "" {
+				cell.NumFmt = format
+			}
+			writeODSCell(body, cell, styles.name(false, cell.NumFmt))
+			totals.add(columnIndex, cell)
+		}
+		body.WriteString(`</table:table-row>`)
+		if wc.Progress != nil {
+			wc.Progress(i+1, len(ts))
+		}
+	}
+	if totals != nil {
+		body.WriteString(`<table:table-row>`)
+		for columnIndex := range columns {
+			cell := &Cell{NumFmt: totals.styles[columnIndex].Format}
+			totals.cell(cell, columnIndex, 1, len(ts))
+			writeODSCell(body, cell, styles.name(true, cell.NumFmt))
+		}
+		body.WriteString(`</table:table-row>`)
+	}
+	body.WriteString(`</table:table>`)
+
+	zw := zip.NewWriter(w)
+	// The mime type comes first and uncompressed, to be recognized by its offset
+	mimeType, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(mimeType, odsMimeType); err != nil {
+		return err
+	}
+	manifest, err := zw.Create("META-INF/manifest.xml")
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(manifest, `%s<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">`+
+		`<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="%s"/>`+
+		`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>`+
+		`</manifest:manifest>`, xml.Header, odsMimeType); err != nil {
+		return err
+	}
+	content, err := zw.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(content, `%s<office:document-content xmlns:office="%s" xmlns:table="%s" xmlns:text="%s"`+
+		` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"`+
+		` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:of="urn:oasis:names:tc:opendocument:xmlns:of:1.2"`+
+		` office:version="1.2"><office:automatic-styles>%s</office:automatic-styles><office:body><office:spreadsheet>`,
+		xml.Header, odsOfficeNamespace, odsTableNamespace, odsTextNamespace, styles.xml()); err != nil {
+		return err
+	}
+	if _, err = body.WriteTo(content); err != nil {
+		return err
+	}
+	if _, err = io.WriteString(content, `</office:spreadsheet></office:body></office:document-content>`); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeODSCell writes the cell with the style, and its formatted value as text for viewers which do not format.
+func writeODSCell(b *bytes.Buffer, cell *Cell, styleName string) {
+	b.WriteString(`<table:table-cell`)
+	if styleName != "" {
+		fmt.Fprintf(b, ` table:style-name="%s"`, styleName)
+	}
+	if cell.Formula() != "" {
+		fmt.Fprintf(b, ` table:formula="%s"`, xmlAttr(toODSFormula(cell.Formula())))
+	}
+	text := cell.Value
+	if value, err := cell.FormattedValue(); err == nil {
+		text = value
+	}
+	switch cell.Type() {
+	case CellTypeNumeric:
+		if cell.Value == "" {
+			break
+		}
+		if t, err := cell.GetTime(false); err == nil && cell.IsTime() {
+			// The serial number is not exact, e.g. 12:29:59.999 for 12:30
+			fmt.Fprintf(b, ` office:value-type="date" office:date-value="%s"`, t.Round(time.Millisecond).Format("2006-01-02T15:04:05.999"))
+		} else if strings.Contains(cell.NumFmt, "%") {
+			fmt.Fprintf(b, ` office:value-type="percentage" office:value="%s"`, xmlAttr(cell.Value))
+		} else {
+			fmt.Fprintf(b, ` office:value-type="float" office:value="%s"`, xmlAttr(cell.Value))
+		}
+	case CellTypeBool:
+		fmt.Fprintf(b, ` office:value-type="boolean" office:boolean-value="%t"`, cell.Value == "1")
+	default:
+		if text != "" {
+			b.WriteString(` office:value-type="string"`)
+		}
+	}
+	if text == "" {
+		b.WriteString(`/>`)
+		return
+	}
+	b.WriteString(`>`)
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(`<text:p>`)
+		writeODSText(b, line)
+		b.WriteString(`</text:p>`)
+	}
+	b.WriteString(`</table:table-cell>`)
+}
+
+// writeODSText writes the text of a paragraph, keeping the spaces which XML would collapse.
+func writeODSText(b *bytes.Buffer, text string) {
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '\t':
+			b.WriteString(`<text:tab/>`)
+			i++
+		case text[i] == ' ':
+			n := 1
+			for i+n < len(text) && text[i+n] == ' ' {
+				n++
+			}
+			spaces := n
+			if i > 0 && i+n < len(text) {
+				// A single space between other text is kept
+				b.WriteByte(' ')
+				spaces--
+			}
+			if spaces > 0 {
+				fmt.Fprintf(b, `<text:s text:c="%d"/>`, spaces)
+			}
+			i += n
+		default:
+			j := i
+			for j < len(text) && text[j] != ' ' && text[j] != '\t' {
+				j++
+			}
+			_ = xml.EscapeText(b, []byte(text[i:j]))
+			i = j
+		}
+	}
+}
+
+// name returns the name of the cell style, "" for the default style.
+func (s *odsStyles) name(bold bool, numFmt string) string {
+	if numFmt == "general" {
+		numFmt = ""
+	}
+	key := odsStyleKey{bold: bold, numFmt: numFmt}
+	if key == (odsStyleKey{}) {
+		return ""
+	}
+	name, ok := s.names[key]
+	if !ok {
+		s.keys = append(s.keys, key)
+		name = fmt.Sprintf("ce%d", len(s.keys))
+		s.names[key] = name
+	}
+	return name
+}
+
+// xml returns the cell styles and their number styles.
+func (s *odsStyles) xml() string {
+	b := &strings.Builder{}
+	for i, key := range s.keys {
+		dataStyle := odsDataStyle(fmt.Sprintf("N%d", i+1), key.numFmt)
+		b.WriteString(dataStyle)
+		fmt.Fprintf(b, `<style:style style:name="ce%d" style:family="table-cell"`, i+1)
+		if dataStyle != "" {
+			fmt.Fprintf(b, ` style:data-style-name="N%d"`, i+1)
+		}
+		b.WriteString(`>`)
+		if key.bold {
+			b.WriteString(`<style:text-properties fo:font-weight="bold"/>`)
+		}
+		b.WriteString(`</style:style>`)
+	}
+	return b.String()
+}
+
+// odsDataStyle returns the number style of an Excel number format, "" if it is not supported.
+// Dates and times are written as year-month-day and hours:minutes:seconds.
+func odsDataStyle(name, numFmt string) string {
+	if numFmt == "" {
+		return ""
+	}
+	if (&Cell{NumFmt: numFmt, cellType: CellTypeNumeric}).IsTime() {
+		lower := strings.ToLower(numFmt)
+		hasDate, hasTime := strings.ContainsAny(lower, "yd"), strings.ContainsAny(lower, "hs")
+		element := "date-style"
+		if !hasDate {
+			element = "time-style"
+		}
+		b := &strings.Builder{}
+		fmt.Fprintf(b, `<number:%s style:name="%s">`, element, name)
+		if hasDate {
+			b.WriteString(`<number:year number:style="long"/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/>`)
+		}
+		if hasDate && hasTime {
+			b.WriteString(`<number:text> </number:text>`)
+		}
+		if hasTime {
+			b.WriteString(`<number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/><number:text>:</number:text><number:seconds number:style="long"/>`)
+		}
+		fmt.Fprintf(b, `</number:%s>`, element)
+		return b.String()
+	}
+	match := numberFormatRegexp.FindStringSubmatch(numFmt)
+	if match == nil {
+		return ""
+	}
+	grouping := ""
+	if match[1] != "" {
+		grouping = ` number:grouping="true"`
+	}
+	number := fmt.Sprintf(`<number:number number:decimal-places="%d" number:min-decimal-places="%d" number:min-integer-digits="1"%s/>`,
+		len(match[2]), len(match[2]), grouping)
+	if match[3] != "" {
+		return fmt.Sprintf(`<number:percentage-style style:name="%s">%s<number:text>%%</number:text></number:percentage-style>`, name, number)
+	}
+	return fmt.Sprintf(`<number:number-style style:name="%s">%s</number:number-style>`, name, number)
+}
@@ -0,0 +1,270 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+type odsTmp struct {
+	Name    string    `excel:"Name"`
+	Count   int       `excel:"Count"`
+	Amount  float64   `excel:"Amount,format=#,##0.00"`
+	Active  bool      `excel:"Active"`
+	Created time.Time `excel:"Created,format=yyyy-mm-dd hh:mm:ss"`
+	Total   float64   `excel:"Total,formula={Amount}*{Count}"`
+}
+
+func (*odsTmp) WriteConfigure(wc *WriteConfig) {
+	wc.SheetName = "Orders"
+	wc.HeaderStyle = CellStyle{Bold: true}
+	wc.TotalsRow = true
+}
+
+func (*odsTmp) ReadConfigure(rc *ReadConfig) {
+	rc.SheetName = "Orders"
+	// Skip the totals row
+	rc.UnmarshalErrorHandling = UnmarshalErrorIgnore
+}
+
+var odsTmpData = []*odsTmp{
+	{"apple  and\tpear", 3, 1234.5, true, time.Date(2022, 5, 6, 12, 30, 0, 0, time.UTC), 0},
+	{" two\nlines ", 2, 0.25, false, time.Date(2022, 5, 7, 0, 0, 0, 0, time.UTC), 0},
+}
+
+// odsFile returns an OpenDocument spreadsheet with the content.xml.
+func odsFile(t *testing.T, mimeType, content string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, part := range []struct{ name, content string }{{"mimetype", mimeType}, {"content.xml", content}} {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		_, _ = io.WriteString(w, part.content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+func TestWriteODS(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteODSTo(buf, odsTmpData); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	data := buf.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, "mimetype", zr.File[0].Name)
+	equal(t, zip.Store, zr.File[0].Method)
+	r, _ := zr.Open("content.xml")
+	content, _ := io.ReadAll(r)
+	for _, expected := range []string{
+		`<table:table table:name="Orders">`,
+		`<number:number-style style:name="N2"><number:number number:decimal-places="2" number:min-decimal-places="2" number:min-integer-digits="1" number:grouping="true"/></number:number-style>`,
+		`<style:style style:name="ce1" style:family="table-cell"><style:text-properties fo:font-weight="bold"/></style:style>`,
+		`<table:table-cell table:style-name="ce2" office:value-type="float" office:value="1234.5"><text:p>1234.50</text:p></table:table-cell>`,
+		`<table:table-cell table:style-name="ce3" office:value-type="date" office:date-value="2022-05-06T12:30:00">`,
+		`<table:table-cell table:formula="of:=[.C2]*[.B2]" office:value-type="float" office:value="3703.5"><text:p>3703.5</text:p></table:table-cell>`,
+		`<text:p>apple <text:s text:c="1"/>and<text:tab/>pear</text:p>`,
+		`<text:p><text:s text:c="1"/>two</text:p><text:p>lines<text:s text:c="1"/></text:p>`,
+		`office:value-type="boolean" office:boolean-value="true"><text:p>TRUE</text:p>`,
+		`table:formula="of:=SUM([.B2:.B3])" office:value-type="float" office:value="5"><text:p>5</text:p>`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("test failed: content.xml misses %s", expected)
+		}
+	}
+
+	models, err := ReadODS[*odsTmp](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 3, len(models))
+	for i, m := range models[:2] {
+		equal(t, odsTmpData[i].Name, m.Name)
+		equal(t, odsTmpData[i].Count, m.Count)
+		equal(t, odsTmpData[i].Amount, m.Amount)
+		equal(t, odsTmpData[i].Active, m.Active)
+		equal(t, odsTmpData[i].Created, m.Created.Round(time.Second))
+		equal(t, m.Amount*float64(m.Count), m.Total)
+	}
+	equal(t, "Total", models[2].Name)
+	equal(t, 5, models[2].Count)
+
+	file := path.Join(t.TempDir(), "tmp.ods")
+	if err = WriteODS(file, odsTmpData); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if models, err = ReadODSFile[*odsTmp](file); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 3, len(models))
+}
+
+type odsPercentTmp struct {
+	Share float64 `excel:"Share,format=0.0%"`
+}
+
+func (*odsPercentTmp) WriteConfigure(_ *WriteConfig) {}
+func (*odsPercentTmp) ReadConfigure(_ *ReadConfig)   {}
+
+func TestWriteODSPercentage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteODSTo(buf, []*odsPercentTmp{{0.125}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	data := buf.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	r, _ := zr.Open("content.xml")
+	content, _ := io.ReadAll(r)
+	if expected := `office:value-type="percentage" office:value="0.125"`; !strings.Contains(string(content), expected) {
+		t.Errorf("test failed: content.xml misses %s", expected)
+	}
+	models, err := ReadODS[*odsPercentTmp](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []*odsPercentTmp{{0.125}}, models)
+}
+
+type readODSTmp struct {
+	Name   string    `excel:"Name"`
+	Day    time.Time `excel:"Day"`
+	Clock  string    `excel:"Clock"`
+	Done   bool      `excel:"Done"`
+	Amount float64   `excel:"Amount"`
+	Note   string    `excel:"Note"`
+}
+
+func TestReadODS(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+ xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+ xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0">
+<office:body><office:spreadsheet>
+<table:table table:name="Other"><table:table-row><table:table-cell office:value-type="string"><text:p>not read</text:p></table:table-cell></table:table-row></table:table>
+<table:table table:name="Data">
+<table:table-column table:number-columns-repeated="1024"/>
+<table:table-header-rows><table:table-row>
+<table:table-cell office:value-type="string"><text:p>Name</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>Day</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>Clock</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>Done</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>Amount</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>Note</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="1018"/>
+</table:table-row></table:table-header-rows>
+<table:table-row table:number-rows-repeated="2">
+<table:table-cell office:value-type="string" calcext:value-type="string"><office:annotation><text:p>comment</text:p></office:annotation><text:p>a<text:span>pp</text:span>le<text:s text:c="2"/>pie</text:p></table:table-cell>
+<table:table-cell office:value-type="date" office:date-value="2022-05-06"><text:p>05/06/22</text:p></table:table-cell>
+<table:table-cell office:value-type="time" office:time-value="PT12H30M00S"><text:p>12:30:00</text:p></table:table-cell>
+<table:table-cell office:value-type="boolean" office:boolean-value="true"><text:p>TRUE</text:p></table:table-cell>
+<table:table-cell office:value-type="percentage" office:value="0.125" table:formula="of:=[.Z1]/8"><text:p>12.50%</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>first</text:p><text:p>second</text:p></table:table-cell>
+</table:table-row>
+<table:table-row table:number-rows-repeated="3"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+<table:table-row><table:covered-table-cell/><table:table-cell table:number-columns-repeated="3"/><table:table-cell office:value-type="float" office:value="1"><text:p>1</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+</office:spreadsheet></office:body></office:document-content>`
+	data := odsFile(t, odsMimeType, content)
+	wb, err := openODSWorkbook(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []string{"Other", "Data"}, wb.sheetNames())
+	sheet, err := wb.sheet(1)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	maxRow, maxCol, err := sheet.dimension()
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	// The repeated empty rows and cells at the end are not read
+	equal(t, []int{7, 6}, []int{maxRow, maxCol})
+	var cells []*Cell
+	for row := range sheet.rows() {
+		if row.index == 1 {
+			cells = row.cells
+		}
+	}
+	equal(t, "Z1/8", cells[4].Formula())
+
+	models, err := ReadODS[*readODSTmp](bytes.NewReader(data), int64(len(data)), WithSheetName("Data"), WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 6, len(models))
+	for _, m := range models[:2] {
+		equal(t, readODSTmp{"apple  pie", time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC), "12:30:00", true, 0.125, "first\nsecond"}, *m)
+	}
+	equal(t, readODSTmp{}, *models[2])
+	equal(t, readODSTmp{Amount: 1}, *models[5])
+
+	_, err = ReadODS[*readODSTmp](bytes.NewReader(data), int64(len(data)), WithSheetIndex(2))
+	equal(t, ErrSheetIndexOutOfRange, err)
+
+	data = odsFile(t, "application/zip", content)
+	if _, err = ReadODS[*readODSTmp](bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidWorkbook) {
+		t.Error("test failed: expected ErrInvalidWorkbook for another mime type")
+	}
+}
+
+func TestODSFormula(t *testing.T) {
+	equal(t, `of:=[.C2]*[.$D$2]+SUM([.A2:.A10];2)&"A1,B2"`, toODSFormula(`C2*$D$2+SUM(A2:A10,2)&"A1,B2"`))
+	equal(t, `of:=LOG10([.A2])`, toODSFormula(`LOG10(A2)`))
+	equal(t, `C2*$D$2+SUM(A2:A10,2)&"A1;B2"`, fromODSFormula(`of:=[.C2]*[.$D$2]+SUM([.A2:.A10];2)&"A1;B2"`))
+	equal(t, `A1+1`, fromODSFormula(`=[.A1]+1`))
+}
+
+func TestFormat(t *testing.T) {
+	for _, format := range []Format{FormatXLSX, FormatCSV, FormatTSV, FormatODS} {
+		buf := &bytes.Buffer{}
+		if err := WriteFormatTo(buf, format, csvTmpData); err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		models, err := ReadFormat[*csvTmp](bytes.NewReader(buf.Bytes()), int64(buf.Len()), format)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, 2, len(models))
+		equal(t, csvTmpData[1].Name, models[1].Name)
+		equal(t, csvTmpData[1].Created, models[1].Created)
+		if format.ContentType() == "application/octet-stream" {
+			t.Errorf("test failed: no content type for %s", format)
+		}
+	}
+	equal(t, "application/octet-stream", Format("pdf").ContentType())
+	if err := WriteFormatTo(&bytes.Buffer{}, Format("pdf"), csvTmpData); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Error("test failed: expected ErrUnsupportedFormat")
+	}
+	if _, err := ReadFormat[*csvTmp](bytes.NewReader(nil), 0, Format("pdf")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Error("test failed: expected ErrUnsupportedFormat")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+
+	"golang.org/x/text/encoding"
+)
+
+type (
+	// ReadOption configures a single read call.
+	// Options are applied in the given order, after the default configuration
+	// and after T.ReadConfigure, if T implements ReadConfigurator.
+	ReadOption  func(ro *readOptions)
+	readOptions struct {
+		rc      *ReadConfig
+		filters []readFilter
+	}
+	// readFilter is a filter func of WithFilter with the type it filters,
+	// checked against the row type of the read call.
+	readFilter struct {
+		typ    reflect.Type
+		filter func(t any) (add bool)
+	}
+)
+
+// WithReadConfig applies a configuration func to the ReadConfig of a single read call.
+// Use it for all settings without a dedicated option.
+func WithReadConfig(configure func(rc *ReadConfig)) ReadOption {
+	return func(ro *readOptions) { configure(ro.rc) }
+}
+
+// WithTagName sets ReadConfig.TagName.
+func WithTagName(tagName string) ReadOption {
+	return func(ro *readOptions) { ro.rc.TagName = tagName }
+}
+
+// WithSheetName sets ReadConfig.SheetName.
+func WithSheetName(sheetName string) ReadOption {
+	return func(ro *readOptions) { ro.rc.SheetName = sheetName }
+}
+
+// WithSheetIndex sets ReadConfig.SheetIndex.
+// It also clears ReadConfig.SheetName, which would otherwise take precedence.
+func WithSheetIndex(sheetIndex int) ReadOption {
+	return func(ro *readOptions) {
+		ro.rc.SheetName = ""
+		ro.rc.SheetIndex = sheetIndex
+	}
+}
+
+// WithHeaderRow sets ReadConfig.HeaderRowIndex.
+// If ReadConfig.DataStartRowIndex is not below the header row,
+// it is moved to the row right after the header row.
+func WithHeaderRow(headerRowIndex int) ReadOption {
+	return func(ro *readOptions) {
+		ro.rc.HeaderRowIndex = headerRowIndex
+		if ro.rc.DataStartRowIndex <= headerRowIndex {
+			ro.rc.DataStartRowIndex = headerRowIndex + 1
+		}
+	}
+}
+
+// WithNoHeaderRow sets ReadConfig.NoHeaderRow.
+// The data start row is not changed.
+func WithNoHeaderRow() ReadOption {
+	return func(ro *readOptions) { ro.rc.NoHeaderRow = true }
+}
+
+// WithStrictHeaders sets ReadConfig.StrictHeaders.
+func WithStrictHeaders() ReadOption {
+	return func(ro *readOptions) { ro.rc.StrictHeaders = true }
+}
+
+// WithDataStartRow sets ReadConfig.DataStartRowIndex.
+func WithDataStartRow(dataStartRowIndex int) ReadOption {
+	return func(ro *readOptions) { ro.rc.DataStartRowIndex = dataStartRowIndex }
+}
+
+// WithTrimSpace sets ReadConfig.TrimSpace.
+func WithTrimSpace(trimSpace bool) ReadOption {
+	return func(ro *readOptions) { ro.rc.TrimSpace = trimSpace }
+}
+
+// WithFallbackDateFormats sets ReadConfig.FallbackDateFormats.
+func WithFallbackDateFormats(formats ...string) ReadOption {
+	return func(ro *readOptions) { ro.rc.FallbackDateFormats = formats }
+}
+
+// WithUnmarshalErrorHandling sets ReadConfig.UnmarshalErrorHandling.
+func WithUnmarshalErrorHandling(handling UnmarshalErrorHandling) ReadOption {
+	return func(ro *readOptions) { ro.rc.UnmarshalErrorHandling = handling }
+}
+
+// WithMaxUnmarshalErrors sets ReadConfig.MaxUnmarshalErrors.
+func WithMaxUnmarshalErrors(maxUnmarshalErrors uint64) ReadOption {
+	return func(ro *readOptions) { ro.rc.MaxUnmarshalErrors = maxUnmarshalErrors }
+}
+
+// WithHeaderNormalizer sets ReadConfig.HeaderNormalizer.
+// Several normalizers are combined with NormalizeHeaders.
+func WithHeaderNormalizer(normalizers ...HeaderNormalizerFunc) ReadOption {
+	return func(ro *readOptions) { ro.rc.HeaderNormalizer = NormalizeHeaders(normalizers...) }
+}
+
+// WithUnmarshalFunc registers the unmarshal func for fields of type `V` in ReadConfig.UnmarshalFuncs.
+// The map of the configuration is copied, not modified.
+func WithUnmarshalFunc[V any](unmarshalFunc UnmarshalExcelFunc) ReadOption {
+	return func(ro *readOptions) {
+		ro.rc.UnmarshalFuncs = maps.Clone(ro.rc.UnmarshalFuncs)
+		if ro.rc.UnmarshalFuncs == nil {
+			ro.rc.UnmarshalFuncs = make(map[reflect.Type]UnmarshalExcelFunc)
+		}
+		ro.rc.UnmarshalFuncs[reflect.TypeFor[V]()] = unmarshalFunc
+	}
+}
+
+// WithHeaderUnmarshalFunc registers the unmarshal func for the field with the header
+// in ReadConfig.HeaderUnmarshalFuncs.
+// The map of the configuration is copied, not modified.
+func WithHeaderUnmarshalFunc(header string, unmarshalFunc UnmarshalExcelFunc) ReadOption {
+	return func(ro *readOptions) {
+		ro.rc.HeaderUnmarshalFuncs = maps.Clone(ro.rc.HeaderUnmarshalFuncs)
+		if ro.rc.HeaderUnmarshalFuncs == nil {
+			ro.rc.HeaderUnmarshalFuncs = make(map[string]UnmarshalExcelFunc)
+		}
+		ro.rc.HeaderUnmarshalFuncs[header] = unmarshalFunc
+	}
+}
+
+// WithProgress sets ReadConfig.Progress.
+func WithProgress(progress ProgressFunc) ReadOption {
+	return func(ro *readOptions) { ro.rc.Progress = progress }
+}
+
+// WithCSVDelimiter sets the delimiter of ReadConfig.CSV, e.g. '\t' for TSV files.
+func WithCSVDelimiter(delimiter rune) ReadOption {
+	return func(ro *readOptions) { ro.rc.CSV.Delimiter = delimiter }
+}
+
+// WithCSVEncoding sets the encoding of ReadConfig.CSV, e.g. simplifiedchinese.GB18030.
+func WithCSVEncoding(enc encoding.Encoding) ReadOption {
+	return func(ro *readOptions) { ro.rc.CSV.Encoding = enc }
+}
+
+// WithFilter adds a filter func to the read call.
+// Rows for which any filter func returns false are not returned.
+// The row type of the read call must be assignable to `T`, i.e. `T` is the row type
+// or an interface it implements. Go cannot check this at compile time,
+// since options are not typed by the row, so the read call fails with ErrInvalidFilter instead,
+// before reading the file.
+func WithFilter[T any](filterFunc func(t T) (add bool)) ReadOption {
+	return func(ro *readOptions) {
+		if filterFunc != nil {
+			ro.filters = append(ro.filters, readFilter{
+				typ:    reflect.TypeFor[T](),
+				filter: func(t any) (add bool) { return filterFunc(t.(T)) },
+			})
+		}
+	}
+}
+
+// newReadConfig builds the effective configuration of a read call:
+// the default configuration, then T.ReadConfigure, then the options.
+func newReadConfig[T any](opts ...ReadOption) (*ReadConfig, []func(t T) (add bool), error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Pointer || typ.Elem().Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("%w, got %s", ErrInvalidRowType, typ)
+	}
+	ro := &readOptions{rc: defaultReadConfig()}
+	var t T
+	if configurator, ok := any(t).(ReadConfigurator); ok {
+		configurator.ReadConfigure(ro.rc)
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(ro)
+		}
+	}
+	filters := make([]func(t T) (add bool), 0, len(ro.filters))
+	for _, rf := range ro.filters {
+		if !typ.AssignableTo(rf.typ) {
+			return nil, nil, fmt.Errorf("%w: WithFilter[%s] cannot filter %s", ErrInvalidFilter, rf.typ, typ)
+		}
+		filters = append(filters, func(t T) (add bool) { return rf.filter(t) })
+	}
+	return ro.rc, filters, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// Row type without ReadConfigurator, configured by options only.
+type readOptionTmp struct {
+	Name1 string `excel:"Name1"`
+	Name2 string `excel:"Name2"`
+}
+
+func TestReadOptions(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	f := xlsx.NewFile()
+	writeExcel0(f, [][]string{{"Name1"}, {"Sheet1Name"}})
+	sheet, _ := f.AddSheet("Upload")
+	for _, row := range [][]string{
+		{"Report generated at 2024-01-01"},
+		{"Name1", "Name2"},
+		{" Name11 ", "Name22"},
+		{"Name111", "Name222"},
+	} {
+		r := sheet.AddRow()
+		for _, cell := range row {
+			r.AddCell().SetString(cell)
+		}
+	}
+	if err := f.Save(testFile); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("without ReadConfigurator", func(t *testing.T) {
+		models, err := ReadFile[*readOptionTmp](testFile)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readOptionTmp{{Name1: "Sheet1Name"}}, models)
+	})
+	t.Run("sheet name and header row", func(t *testing.T) {
+		models, err := ReadFile[*readOptionTmp](testFile, WithSheetName("Upload"), WithHeaderRow(1), WithTrimSpace(true))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readOptionTmp{{"Name11", "Name22"}, {"Name111", "Name222"}}, models)
+	})
+	t.Run("sheet index and data start row", func(t *testing.T) {
+		models, err := ReadFile[*readOptionTmp](testFile, WithSheetIndex(1), WithHeaderRow(1), WithDataStartRow(3))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readOptionTmp{{"Name111", "Name222"}}, models)
+	})
+	t.Run("options override ReadConfigure", func(t *testing.T) {
+		// readTmp configures SheetName "Sheet1", which is overridden
+		models, err := ReadFile[*readTmp](testFile, WithSheetIndex(1), WithHeaderRow(1))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, 2, len(models))
+		equal(t, "Name11", models[0].Name1) // TrimSpace from ReadConfigure still applies
+	})
+	t.Run("read config", func(t *testing.T) {
+		_, err := ReadFile[*readOptionTmp](testFile, WithReadConfig(func(rc *ReadConfig) { rc.SheetIndex = 5 }))
+		equal(t, ErrSheetIndexOutOfRange, err)
+	})
+	t.Run("filter", func(t *testing.T) {
+		models, err := ReadFile[*readOptionTmp](testFile, WithSheetName("Upload"), WithHeaderRow(1),
+			WithFilter(func(t *readOptionTmp) (add bool) { return t.Name2 == "Name222" }))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readOptionTmp{{"Name111", "Name222"}}, models)
+	})
+	t.Run("filter of interface type", func(t *testing.T) {
+		models, err := ReadFile[*readOptionTmp](testFile, WithSheetName("Upload"), WithHeaderRow(1),
+			WithFilter(func(t any) (add bool) { return t.(*readOptionTmp).Name1 == "Name111" }))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readOptionTmp{{"Name111", "Name222"}}, models)
+	})
+	t.Run("filter of other type", func(t *testing.T) {
+		_, err := ReadFile[*readOptionTmp](testFile, WithFilter(func(t *readTmp) (add bool) { return true }))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Error("test failed: expected ErrInvalidFilter, got:", err)
+		}
+		equal(t, "exl: filter func does not match row type: WithFilter[*exl.readTmp] cannot filter *exl.readOptionTmp", err.Error())
+	})
+	t.Run("invalid row type", func(t *testing.T) {
+		_, err := ReadFile[readOptionTmp](testFile)
+		if !errors.Is(err, ErrInvalidRowType) {
+			t.Error("test failed: expected ErrInvalidRowType, got:", err)
+		}
+	})
+}
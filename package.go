@@ -0,0 +1,246 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+const (
+	relationshipsNamespace = "http://schemas.openxmlformats.org/package/2006/relationships"
+	relationshipTypePrefix = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/"
+)
+
+type (
+	// xlsxPackage holds the parts of the zip archive of an xlsx file,
+	// to add parts which the xlsx library cannot write, like comments or tables.
+	xlsxPackage struct {
+		names []string
+		parts map[string][]byte
+	}
+	xmlRelationships struct {
+		XMLName       xml.Name          `xml:"Relationships"`
+		Xmlns         string            `xml:"xmlns,attr"`
+		Relationships []xmlRelationship `xml:"Relationship"`
+	}
+	xmlRelationship struct {
+		Id         string `xml:"Id,attr"`
+		Type       string `xml:"Type,attr"`
+		Target     string `xml:"Target,attr"`
+		TargetMode string `xml:"TargetMode,attr,omitempty"`
+	}
+)
+
+// marshalPackage writes the file into a package, to be extended before it is written.
+func marshalPackage(f *xlsx.File) (*xlsxPackage, error) {
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err != nil {
+		return nil, err
+	}
+	return readPackage(buf.Bytes())
+}
+
+// readPackage reads the parts of the zip archive.
+func readPackage(data []byte) (*xlsxPackage, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	p := &xlsxPackage{parts: make(map[string][]byte, len(zr.File))}
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		p.set(zf.Name, data)
+	}
+	return p, nil
+}
+
+// Write writes the package as zip archive.
+func (p *xlsxPackage) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, name := range p.names {
+		pw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err = pw.Write(p.parts[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (p *xlsxPackage) set(name string, data []byte) {
+	if _, ok := p.parts[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.parts[name] = data
+}
+
+// freeName returns the first name of the pattern, e.g. "xl/comments%d.xml",
+// which is not used yet, counting from 1.
+func (p *xlsxPackage) freeName(pattern string) string {
+	for i := 1; ; i++ {
+		if name := fmt.Sprintf(pattern, i); p.parts[name] == nil {
+			return name
+		}
+	}
+}
+
+// sheetPart returns the part name of a worksheet, as written by the xlsx library.
+func sheetPart(sheetIndex int) string {
+	return fmt.Sprintf("xl/worksheets/sheet%d.xml", sheetIndex+1)
+}
+
+// addContentType registers the content type of a part,
+// or of an extension if the name starts with a dot.
+func (p *xlsxPackage) addContentType(name, contentType string) {
+	const part = "[Content_Types].xml"
+	var entry string
+	if ext, ok := strings.CutPrefix(name, "."); ok {
+		entry = fmt.Sprintf(`<Default Extension="%s" ContentType="%s"></Default>`, ext, contentType)
+	} else {
+		entry = fmt.Sprintf(`<Override PartName="/%s" ContentType="%s"></Override>`, name, contentType)
+	}
+	types := string(p.parts[part])
+	if strings.Contains(types, entry) {
+		return
+	}
+	p.set(part, []byte(strings.Replace(types, "</Types>", entry+"</Types>", 1)))
+}
+
+// addRelationship adds a relationship from the part to the target part
+// and returns its id. Both are full part names.
+func (p *xlsxPackage) addRelationship(from, relType, to string) (string, error) {
+	dir, _ := path.Split(from)
+	relsPart := relationshipsPart(from)
+	rels := xmlRelationships{Xmlns: relationshipsNamespace}
+	if data := p.parts[relsPart]; data != nil {
+		if err := xml.Unmarshal(data, &rels); err != nil {
+			return "", err
+		}
+	}
+	ids := make(map[string]bool, len(rels.Relationships))
+	for _, r := range rels.Relationships {
+		ids[r.Id] = true
+	}
+	id := ""
+	for i := len(rels.Relationships) + 1; id == "" || ids[id]; i++ {
+		id = fmt.Sprintf("rId%d", i)
+	}
+	rels.Relationships = append(rels.Relationships, xmlRelationship{
+		Id:     id,
+		Type:   relationshipTypePrefix + relType,
+		Target: relativePath(dir, to),
+	})
+	data, err := xml.Marshal(rels)
+	if err != nil {
+		return "", err
+	}
+	p.set(relsPart, append([]byte(xml.Header), data...))
+	return id, nil
+}
+
+// writePackage writes the file to w, extended by the func, if any.
+func writePackage(f *xlsx.File, w io.Writer, extend func(p *xlsxPackage) error) error {
+	if extend == nil {
+		return f.Write(w)
+	}
+	p, err := marshalPackage(f)
+	if err != nil {
+		return err
+	}
+	if err = extend(p); err != nil {
+		return err
+	}
+	return p.Write(w)
+}
+
+// savePackage writes the file to the path, extended by the func, if any.
+func savePackage(f *xlsx.File, path string, extend func(p *xlsxPackage) error) error {
+	target, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = writePackage(f, target, extend); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// relationships returns the relationships of the part.
+func (p *xlsxPackage) relationships(from string) ([]xmlRelationship, error) {
+	var rels xmlRelationships
+	data := p.parts[relationshipsPart(from)]
+	if data == nil {
+		return nil, nil
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, err
+	}
+	return rels.Relationships, nil
+}
+
+// relationshipsPart returns the name of the part holding the relationships of the part.
+func relationshipsPart(from string) string {
+	dir, file := path.Split(from)
+	return dir + "_rels/" + file + ".rels"
+}
+
+// targetPart returns the part name of a relationship target.
+func targetPart(from, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(from), target)
+}
+
+// relativePath returns the path of the part relative to the directory.
+func relativePath(dir, to string) string {
+	up := ""
+	for !strings.HasPrefix(to, dir) {
+		dir = dir[:strings.LastIndex(strings.TrimSuffix(dir, "/"), "/")+1]
+		up += "../"
+	}
+	return up + strings.TrimPrefix(to, dir)
+}
+
+// insertElement inserts the element into the XML of the part,
+// in front of the first of the given tags found,
+// to keep the element order required by the schema.
+func (p *xlsxPackage) insertElement(part, element string, before ...string) error {
+	content := string(p.parts[part])
+	for _, tag := range before {
+		if i := strings.Index(content, tag); i >= 0 {
+			p.set(part, []byte(content[:i]+element+content[i:]))
+			return nil
+		}
+	}
+	return fmt.Errorf("exl: cannot insert %s into %s", element, part)
+}
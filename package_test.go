@@ -0,0 +1,70 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"strings"
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+func TestRelativePath(t *testing.T) {
+	equal(t, "../comments1.xml", relativePath("xl/worksheets/", "xl/comments1.xml"))
+	equal(t, "../drawings/vmlDrawing1.vml", relativePath("xl/worksheets/", "xl/drawings/vmlDrawing1.vml"))
+	equal(t, "sheet1.xml", relativePath("xl/worksheets/", "xl/worksheets/sheet1.xml"))
+	equal(t, "xl/workbook.xml", relativePath("", "xl/workbook.xml"))
+}
+
+func TestXlsxPackage(t *testing.T) {
+	f := xlsx.NewFile()
+	if _, err := f.AddSheet("Sheet1"); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	p, err := marshalPackage(f)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := sheetPart(0)
+	if p.parts[sheet] == nil {
+		t.Fatal("test failed: missing part " + sheet)
+	}
+	equal(t, "xl/comments1.xml", p.freeName("xl/comments%d.xml"))
+	p.set("xl/comments1.xml", []byte("<comments/>"))
+	equal(t, "xl/comments2.xml", p.freeName("xl/comments%d.xml"))
+
+	id1, err := p.addRelationship(sheet, "comments", "xl/comments1.xml")
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	id2, _ := p.addRelationship(sheet, "comments", "xl/comments2.xml")
+	equal(t, "rId1", id1)
+	equal(t, "rId2", id2)
+	rels := string(p.parts["xl/worksheets/_rels/sheet1.xml.rels"])
+	if !strings.Contains(rels, `Target="../comments2.xml"`) {
+		t.Error("test failed: unexpected relationships: " + rels)
+	}
+
+	p.addContentType(".vml", "application/vnd.openxmlformats-officedocument.vmlDrawing")
+	p.addContentType(".vml", "application/vnd.openxmlformats-officedocument.vmlDrawing")
+	equal(t, 1, strings.Count(string(p.parts["[Content_Types].xml"]), `Extension="vml"`))
+
+	if err = p.insertElement(sheet, "<legacyDrawing/>", "<extLst", "</worksheet>"); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(p.parts[sheet])), "<legacyDrawing/></worksheet>") {
+		t.Error("test failed: element not inserted before </worksheet>")
+	}
+	if err = p.insertElement(sheet, "<x/>", "<missing"); err == nil {
+		t.Error("test failed: expected error")
+	}
+}
@@ -12,20 +12,32 @@
 package exl
 
 import (
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"codeberg.org/tealeg/xlsx/v4"
 )
 
 type (
-	ReadConfigurator             interface{ ReadConfigure(rc *ReadConfig) }
-	RowUnmarshalErrorHandlerFunc func(*xlsx.Cell, *reflect.Value, FieldInfo)
-	UnusedColumnsHandlerFunc     func(*xlsx.Cell, *reflect.Value, FieldInfo)
+	ReadConfigurator interface{ ReadConfigure(rc *ReadConfig) }
+	// RowValidator is implemented by row types with rules spanning several fields,
+	// e.g. "EndDate must be after StartDate".
+	// ValidateExcelRow is called after a row has been unmarshalled without errors.
+	// A returned error is handled like an unmarshal error, as RowError,
+	// or as FieldError if it is one, see FieldError.ColumnHeader.
+	RowValidator interface{ ValidateExcelRow() error }
+	// ProgressFunc reports the progress of a read or write, see ReadConfig.Progress.
+	ProgressFunc                 func(processed, total int)
+	RowUnmarshalErrorHandlerFunc func(*Cell, *reflect.Value, FieldInfo)
+	UnusedColumnsHandlerFunc     func(*Cell, *reflect.Value, FieldInfo)
 	ReadConfig                   struct {
 		// The tag name to use when looking for fields in the target struct.
 		// Defaults to "excel".
@@ -84,6 +96,46 @@ type (
 		// Handler function for columns not present in struct.
 		// Defaults to nil.
 		UnusedColumnsHandler UnusedColumnsHandlerFunc
+		// Normalizer applied to the headers of the sheet and to the tags
+		// before they are matched, e.g. NormalizeAll.
+		// Defaults to nil, headers have to match the tags exactly.
+		HeaderNormalizer HeaderNormalizerFunc
+		// The sheet has no header row, HeaderRowIndex is not used.
+		// Fields bind to the columns by their position in the struct,
+		// or by the tag options "col" and "idx".
+		// Note that DataStartRowIndex still applies,
+		// set it to 0 if the data starts in the first row.
+		// Defaults to false.
+		NoHeaderRow bool
+		// Require a column for every field and a field for every column,
+		// see HeaderError. Columns without header are not reported.
+		// Without strict mode, only fields with the tag option "required"
+		// need a column, e.g. `excel:"SKU,required"`.
+		// Defaults to false.
+		StrictHeaders bool
+		// The tag name for validation rules, which are checked after a cell is unmarshalled,
+		// e.g. `validate:"required,min=0,max=100,oneof=A|B"`.
+		// Violations are handled like unmarshal errors, with a ValidationError.
+		// Defaults to "validate".
+		ValidateTagName string
+		// Unmarshal funcs for fields of a specific type, e.g. `type CNY int64` or uuid.UUID.
+		// They take precedence over ExcelUnmarshaler, TextUnmarshaler and DefaultUnmarshalFuncs.
+		// A pointer field uses the func of its element type, unless its pointer type is registered.
+		// Defaults to nil.
+		UnmarshalFuncs map[reflect.Type]UnmarshalExcelFunc
+		// Unmarshal funcs for fields by their header in the tag,
+		// taking precedence over UnmarshalFuncs.
+		// The func gets the field as is, pointers are not allocated.
+		// Defaults to nil.
+		HeaderUnmarshalFuncs map[string]UnmarshalExcelFunc
+		// Called after every data row, with the number of data rows processed so far
+		// and the total number of data rows, from the last row of the sheet,
+		// or -1 if unknown, e.g. for CSV files.
+		// Rows with errors and rows rejected by a filter func count as processed.
+		// Defaults to nil.
+		Progress ProgressFunc
+		// Delimiter and encoding of CSV files, see ReadCSV.
+		CSV CSVConfig
 	}
 	UnmarshalErrorHandling uint8
 	FieldError             struct {
@@ -92,10 +144,23 @@ type (
 		ColumnHeader string
 		Err          error
 	}
+	// RowError is an error of a whole row, returned by RowValidator.
+	RowError struct {
+		RowIndex int // 0-based row index. Printed as 1-based row number in error text.
+		Err      error
+	}
 	ContentError struct {
 		FieldErrors  []FieldError
+		RowErrors    []RowError
 		LimitReached bool
 	}
+	// HeaderError is returned before any data row is read,
+	// if the sheet lacks columns for required fields,
+	// or, with ReadConfig.StrictHeaders, if the headers do not match the fields.
+	HeaderError struct {
+		Missing    []string // Headers of the fields without column
+		Unexpected []string // Headers of the columns without field
+	}
 )
 
 var (
@@ -105,8 +170,12 @@ var (
 	_ interface {
 		Unwrap() error
 	} = FieldError{}
+	// Ensure RowError implements the error interface
+	_ error = RowError{}
 	// Ensure ContentError implements the error interface
 	_ error = ContentError{}
+	// Ensure HeaderError implements the error interface
+	_ error = HeaderError{}
 )
 
 // Error implements error.
@@ -120,26 +189,71 @@ func (e FieldError) Unwrap() error {
 	return e.Err
 }
 
+// Error implements error.
+func (e RowError) Error() string {
+	return fmt.Sprintf("error validating row %d: %s", e.RowIndex+1, e.Err.Error())
+}
+
+// Unwrap
+// Error implements the anonymous unwrap interface used by errors.Unwrap and others.
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
 // Error implements error.
 func (e ContentError) Error() string {
 	if e.LimitReached {
-		return fmt.Sprintf("too many (%d) errors reading data from Excel", len(e.FieldErrors))
+		return fmt.Sprintf("too many (%d) errors reading data from Excel", e.count())
 	} else {
-		return fmt.Sprintf("%d errors reading data from Excel", len(e.FieldErrors))
+		return fmt.Sprintf("%d errors reading data from Excel", e.count())
 	}
 }
 
+func (e ContentError) count() int {
+	return len(e.FieldErrors) + len(e.RowErrors)
+}
+
 // Unwrap
 // Error implements the anonymous unwrap interface used by errors.Unwrap and others.
 func (e ContentError) Unwrap() []error {
 	// Slice needs to be type-adjusted
-	errs := make([]error, len(e.FieldErrors))
-	for i, v := range e.FieldErrors {
-		errs[i] = v
+	errs := make([]error, 0, e.count())
+	for _, v := range e.FieldErrors {
+		errs = append(errs, v)
+	}
+	for _, v := range e.RowErrors {
+		errs = append(errs, v)
 	}
 	return errs
 }
 
+// Error implements error.
+func (e HeaderError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing columns %s", quoteHeaders(e.Missing)))
+	}
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, fmt.Sprintf("unexpected columns %s", quoteHeaders(e.Unexpected)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Is
+// Reports ErrMissingColumns and ErrNoDestinationField, depending on the headers listed.
+func (e HeaderError) Is(target error) bool {
+	return (target == ErrMissingColumns && len(e.Missing) > 0) ||
+		(target == ErrNoDestinationField && len(e.Unexpected) > 0)
+}
+
+func quoteHeaders(headers []string) string {
+	quoted := make([]string, len(headers))
+	for i, h := range headers {
+		quoted[i] = strconv.Quote(h)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 const (
 	// UnmarshalErrorIgnore
 	// Ignore any errors during unmarshalling
@@ -157,6 +271,7 @@ var (
 	defaultReadConfig = func() *ReadConfig {
 		return &ReadConfig{
 			TagName:                "excel",
+			ValidateTagName:        "validate",
 			DataStartRowIndex:      1,
 			SkipUnknownColumns:     true,
 			UnmarshalErrorHandling: UnmarshalErrorAbort,
@@ -168,16 +283,12 @@ var (
 	ErrDataStartRowIndexOutOfRange = errors.New("exl: data start row index out of range")
 	ErrNoUnmarshaler               = errors.New("no unmarshaler")
 	ErrNoDestinationField          = errors.New("no destination field with matching tag")
+	ErrMissingColumns              = errors.New("missing columns")
+	ErrInvalidRowType              = errors.New("exl: row type must be a pointer to a struct")
+	ErrInvalidFilter               = errors.New("exl: filter func does not match row type")
+	ErrInvalidTag                  = errors.New("exl: invalid tag option")
 )
 
-func readStrings(maxCol int, row *xlsx.Row) []string {
-	ls := make([]string, maxCol)
-	for i := 0; i < maxCol; i++ {
-		ls[i] = row.GetCell(i).Value
-	}
-	return ls
-}
-
 func GetUnmarshalFunc(destField reflect.Value) UnmarshalExcelFunc {
 	if destField.CanInterface() {
 
@@ -212,7 +323,7 @@ func GetUnmarshalFunc(destField reflect.Value) UnmarshalExcelFunc {
 	unmarshalFunc, ok := DefaultUnmarshalFuncs[kind]
 	if ok {
 		if isPointer {
-			return func(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+			return func(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 				reflect.New(destField.Type())
 				return unmarshalPointer(destValue, cell, params, unmarshalFunc)
 			}
@@ -223,7 +334,27 @@ func GetUnmarshalFunc(destField reflect.Value) UnmarshalExcelFunc {
 	return nil
 }
 
-func unmarshalPointer(destPointer reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters, unmarshalFunc UnmarshalExcelFunc) error {
+// unmarshalFunc returns the unmarshal func registered for the header or the type of the field,
+// or GetUnmarshalFunc if there is none.
+func (rc *ReadConfig) unmarshalFunc(destField reflect.Value, header string) UnmarshalExcelFunc {
+	if unmarshalFunc, ok := rc.HeaderUnmarshalFuncs[header]; ok && unmarshalFunc != nil {
+		return unmarshalFunc
+	}
+	typ := destField.Type()
+	if unmarshalFunc, ok := rc.UnmarshalFuncs[typ]; ok && unmarshalFunc != nil {
+		return unmarshalFunc
+	}
+	if typ.Kind() == reflect.Ptr {
+		if unmarshalFunc, ok := rc.UnmarshalFuncs[typ.Elem()]; ok && unmarshalFunc != nil {
+			return func(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
+				return unmarshalPointer(destValue, cell, params, unmarshalFunc)
+			}
+		}
+	}
+	return GetUnmarshalFunc(destField)
+}
+
+func unmarshalPointer(destPointer reflect.Value, cell *Cell, params *ExcelUnmarshalParameters, unmarshalFunc UnmarshalExcelFunc) error {
 	// Create new pointer to the field value,
 	// as the pointer may be nil
 	elemType := destPointer.Type().Elem()
@@ -240,108 +371,271 @@ func unmarshalPointer(destPointer reflect.Value, cell *xlsx.Cell, params *ExcelU
 // into memory to determine the size, otherwise the zip reader cannot be called.
 // Use one of the other `Read*` methods to avoid reading the whole file into memory
 // before parsing starts - the excel library will copy the file content into memory anyways.
-func Read[T ReadConfigurator](reader io.Reader, filterFunc ...func(t T) (add bool)) ([]T, error) {
+// ReadStream avoids both, e.g. with a multipart.File, which is an io.ReaderAt.
+func Read[T any](reader io.Reader, opts ...ReadOption) ([]T, error) {
 	// since io.Reader does not provide a size, we have to read it all to get the size
 	if bytes, err := io.ReadAll(reader); err != nil {
 		return []T(nil), err
 	} else {
-		return ReadBinary(bytes, filterFunc...)
+		return ReadBinary[T](bytes, opts...)
 	}
 }
 
-// ReadReaderAt opens an xlsx file at the given file path.
+// ReadReaderAt opens an xlsx file from the given io.ReaderAt.
 // Each row is parsed and unmarshalled into a slice of `T`.
-func ReadReaderAt[T ReadConfigurator](reader io.ReaderAt, size int64, filterFunc ...func(t T) (add bool)) ([]T, error) {
+// See ReadStream to read large files with little memory.
+func ReadReaderAt[T any](reader io.ReaderAt, size int64, opts ...ReadOption) ([]T, error) {
 	f, err := xlsx.OpenReaderAt(reader, size)
 	if err != nil {
 		return nil, err
 	}
-	return ReadParsed[T](f, filterFunc...)
+	return ReadParsed[T](f, opts...)
 }
 
 // ReadFile opens an xlsx file at the given file path.
 // Each row is parsed and unmarshalled into a slice of `T`.
-func ReadFile[T ReadConfigurator](file string, filterFunc ...func(t T) (add bool)) ([]T, error) {
+func ReadFile[T any](file string, opts ...ReadOption) ([]T, error) {
 	f, err := xlsx.OpenFile(file)
 	if err != nil {
 		return nil, err
 	}
-	return ReadParsed[T](f, filterFunc...)
+	return ReadParsed[T](f, opts...)
 }
 
 // ReadBinary opens an xlsx file from the provided bytes.
 // Each row is parsed and unmarshalled into a slice of `T`.
-func ReadBinary[T ReadConfigurator](bytes []byte, filterFunc ...func(t T) (add bool)) ([]T, error) {
+func ReadBinary[T any](bytes []byte, opts ...ReadOption) ([]T, error) {
 	f, err := xlsx.OpenBinary(bytes)
 	if err != nil {
 		return nil, err
 	}
-	return ReadParsed[T](f, filterFunc...)
+	return ReadParsed[T](f, opts...)
 }
 
 type FieldInfo struct {
-	reflectFieldIndex int
+	reflectFieldIndex []int
 	Header            string
 	unmarshalFunc     UnmarshalExcelFunc
+	validators        []validateFunc
 }
 
-// ReadParsed opens an already parsed xlsx file directly.
-// Each row is parsed and unmarshalled into a slice of `T`.
-func ReadParsed[T ReadConfigurator](f *xlsx.File, filterFunc ...func(t T) (add bool)) ([]T, error) {
-	var t T
-	rc := defaultReadConfig()
-	t.ReadConfigure(rc)
-	sidx := rc.SheetIndex
-	if len(rc.SheetName) > 0 {
-		for idx, s := range f.Sheets {
-			if s.Name == rc.SheetName {
-				sidx = idx
-				break
+// sheetReader holds everything readSheet needs to turn the rows
+// of one sheet into values of `T`: the effective configuration,
+// the header to field mapping, the unmarshal parameters
+// and the state of the rows read so far.
+type sheetReader[T any] struct {
+	rc              *ReadConfig
+	filters         []func(t T) (add bool)
+	typ             reflect.Type
+	columnFields    []FieldInfo
+	uniqueKeys      []uniqueKey
+	unmarshalConfig *ExcelUnmarshalParameters
+	collector       *errorCollector
+	seen            *uniqueIndex
+}
+
+// readSheet returns an iterator over the data rows of the sheet
+// configured by the ReadConfig in the workbook opened by the func, see Rows.
+// The workbook is opened when the iteration starts.
+// Data rows before the header row are kept until the header row has been read.
+// The context is checked before every row, its error ends the iteration.
+func readSheet[T any](ctx context.Context, open func(rc *ReadConfig) (workbookBackend, error), opts ...ReadOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rc, filters, err := newReadConfig[T](opts...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		wb, err := open(rc)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		src, err := openSheet(wb, rc)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		maxRow, maxCol, err := src.dimension()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		// Without a dimension, the row indexes are checked once the rows are read
+		known := maxRow >= 0
+		if !rc.NoHeaderRow && (rc.HeaderRowIndex < 0 || known && rc.HeaderRowIndex > maxRow-1) {
+			yield(zero, ErrHeaderRowIndexOutOfRange)
+			return
+		}
+		if rc.DataStartRowIndex < 0 || known && rc.DataStartRowIndex > maxRow-1 {
+			yield(zero, ErrDataStartRowIndexOutOfRange)
+			return
+		}
+		var r *sheetReader[T]
+		if rc.NoHeaderRow && known {
+			if r, err = newSheetReader[T](rc, filters, make([]string, maxCol), src.date1904()); err != nil {
+				yield(zero, err)
+				return
+			}
+		}
+		var early []sheetRow
+		processed, total := 0, -1
+		if known {
+			total = maxRow - rc.DataStartRowIndex
+		}
+		// width is the number of columns, of the header or first data row without a dimension
+		width := func(row sheetRow) int {
+			if known {
+				return maxCol
 			}
+			return len(row.cells)
 		}
+		read := func(row sheetRow) bool {
+			t, ok, err := r.read(row)
+			if err != nil {
+				yield(zero, err)
+				return false
+			}
+			processed++
+			if rc.Progress != nil {
+				rc.Progress(processed, total)
+			}
+			return !ok || yield(t, nil)
+		}
+		last := -1
+		for row, err := range src.rows() {
+			if err == nil {
+				err = ctx.Err()
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			last = row.index
+			if row.index < rc.DataStartRowIndex && (r != nil || rc.NoHeaderRow || row.index != rc.HeaderRowIndex) {
+				continue
+			}
+			if r == nil && rc.NoHeaderRow {
+				if r, err = newSheetReader[T](rc, filters, make([]string, width(row)), src.date1904()); err != nil {
+					yield(zero, err)
+					return
+				}
+			}
+			if r != nil {
+				if !read(row) {
+					return
+				}
+				continue
+			}
+			if row.index >= rc.DataStartRowIndex {
+				early = append(early, row)
+			}
+			if row.index == rc.HeaderRowIndex {
+				if r, err = newSheetReader[T](rc, filters, row.strings(width(row)), src.date1904()); err != nil {
+					yield(zero, err)
+					return
+				}
+				for _, row := range early {
+					if !read(row) {
+						return
+					}
+				}
+				early = nil
+			}
+		}
+		if r == nil && rc.NoHeaderRow {
+			yield(zero, ErrDataStartRowIndexOutOfRange)
+		} else if r == nil {
+			yield(zero, ErrHeaderRowIndexOutOfRange)
+		} else if !known && rc.DataStartRowIndex > last {
+			yield(zero, ErrDataStartRowIndexOutOfRange)
+		} else if err := r.collector.err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// newSheetReader maps the columns of the headers to the fields of `T`.
+func newSheetReader[T any](rc *ReadConfig, filters []func(t T) (add bool), headers []string, date1904 bool) (*sheetReader[T], error) {
+	typ := reflect.TypeFor[T]().Elem()
+	fields := typeFields(typ, rc.TagName)
+
+	// Key: Column Index
+	// Value: Index into fields, -1 if no field binds to the column
+	columnToField := make([]int, len(headers))
+	for columnIndex := range columnToField {
+		columnToField[columnIndex] = -1
 	}
-	if sidx < 0 || sidx > len(f.Sheet)-1 {
-		return nil, ErrSheetIndexOutOfRange
+	if rc.NoHeaderRow {
+		// Bind by position
+		for i := range fields {
+			if i < len(columnToField) {
+				columnToField[i] = i
+			}
+		}
+	} else {
+		// Key: Header / Tag name
+		// Value: Index into fields
+		tagToFieldMap := make(map[string]int)
+		for i, sf := range fields {
+			tagToFieldMap[normalizeHeader(rc.HeaderNormalizer, sf.header)] = i
+		}
+		// Aliases never take a header away from another field
+		for i, sf := range fields {
+			for _, alias := range sf.aliases {
+				key := normalizeHeader(rc.HeaderNormalizer, alias)
+				if _, have := tagToFieldMap[key]; !have {
+					tagToFieldMap[key] = i
+				}
+			}
+		}
+		for columnIndex, header := range headers {
+			if i, have := tagToFieldMap[normalizeHeader(rc.HeaderNormalizer, header)]; have {
+				columnToField[columnIndex] = i
+			}
+		}
 	}
-	sheet := f.Sheets[sidx]
-	if rc.HeaderRowIndex < 0 || rc.HeaderRowIndex > sheet.MaxRow-1 {
-		return nil, ErrHeaderRowIndexOutOfRange
+	// Explicit column bindings take precedence over headers and positions
+	for i, sf := range fields {
+		columnIndex, ok, err := sf.column()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for c, fieldIndex := range columnToField {
+			if fieldIndex == i {
+				columnToField[c] = -1
+			}
+		}
+		if columnIndex < len(columnToField) {
+			columnToField[columnIndex] = i
+		}
 	}
-	if rc.DataStartRowIndex < 0 || rc.DataStartRowIndex > sheet.MaxRow-1 {
-		return nil, ErrDataStartRowIndexOutOfRange
+	if err := checkHeaders(rc, fields, headers, columnToField); err != nil {
+		return nil, err
 	}
-	headerRow, _ := sheet.Row(rc.HeaderRowIndex)
-	maxCol := sheet.MaxCol
-	headers := readStrings(maxCol, headerRow)
 
-	// Key: Header / Tag name
-	// Value: Reflection field index
-	tagToFieldMap := make(map[string]int)
 	// Key: Column Index
 	// Value: Unmarshalling Info
 	columnFields := make([]FieldInfo, len(headers))
-
-	typ := reflect.TypeOf(t).Elem()
-	for i := 0; i < typ.NumField(); i++ {
-		if ta := typ.Field(i).Tag; ta != "" {
-			if tt, have := ta.Lookup(rc.TagName); have {
-				tagToFieldMap[tt] = i
-			}
-		}
-	}
+	var uniqueKeys []uniqueKey
 
 	{
 		val := reflect.New(typ).Elem()
 
 		for columnIndex, header := range headers {
-			reflectFieldIndex, have := tagToFieldMap[header]
-			if !have {
+			if columnToField[columnIndex] < 0 {
+				if header == "" && rc.NoHeaderRow {
+					header = xlsx.ColIndexToLetters(columnIndex)
+				}
 				if rc.SkipUnknownColumns {
 					// Skip reading this field
 					columnFields[columnIndex] = FieldInfo{
-						reflectFieldIndex: reflectFieldIndex,
-						Header:            header,
-						unmarshalFunc:     nil,
+						Header:        header,
+						unmarshalFunc: nil,
 					}
 					continue
 				} else {
@@ -349,9 +643,17 @@ func ReadParsed[T ReadConfigurator](f *xlsx.File, filterFunc ...func(t T) (add b
 				}
 			}
 
-			field := val.Field(reflectFieldIndex)
+			sf := fields[columnToField[columnIndex]]
+			if header == "" {
+				header = sf.header
+			}
+			if header == "" {
+				header = xlsx.ColIndexToLetters(columnIndex)
+			}
+			reflectFieldIndex := sf.index
+			field := fieldByIndex(val, reflectFieldIndex)
 
-			unmarshaler := GetUnmarshalFunc(field)
+			unmarshaler := rc.unmarshalFunc(field, sf.header)
 			if unmarshaler == nil {
 				if rc.SkipUnknownTypes {
 					// Skip reading this field
@@ -366,104 +668,268 @@ func ReadParsed[T ReadConfigurator](f *xlsx.File, filterFunc ...func(t T) (add b
 				}
 			}
 
+			validators, err := parseValidateTag(sf.rawTag.Get(rc.ValidateTagName), sf.typ)
+			if err != nil {
+				return nil, fmt.Errorf("%w for column \"%s\" at index %d", err, header, columnIndex)
+			}
+			if enum := tagEnum(sf.tag); enum != nil {
+				validators = append(validators, enumValidator(enum))
+			}
+
 			columnFields[columnIndex] = FieldInfo{
 				reflectFieldIndex: reflectFieldIndex,
 				Header:            header,
 				unmarshalFunc:     unmarshaler,
+				validators:        validators,
+			}
+			if group, ok := sf.tag.Options["unique"]; ok {
+				if group == "" {
+					group = sf.header
+				}
+				uniqueKeys = addUniqueColumn(uniqueKeys, group, columnIndex)
 			}
 		}
 	}
 
-	unmarshalConfig := &ExcelUnmarshalParameters{
-		TrimSpace:           rc.TrimSpace,
-		Date1904:            f.Date1904,
-		FallbackDateFormats: rc.FallbackDateFormats,
+	return &sheetReader[T]{
+		rc:           rc,
+		filters:      filters,
+		typ:          typ,
+		columnFields: columnFields,
+		uniqueKeys:   uniqueKeys,
+		unmarshalConfig: &ExcelUnmarshalParameters{
+			TrimSpace:           rc.TrimSpace,
+			Date1904:            date1904,
+			FallbackDateFormats: rc.FallbackDateFormats,
+		},
+		collector: &errorCollector{rc: rc},
+		seen:      newUniqueIndex(uniqueKeys),
+	}, nil
+}
+
+// checkHeaders returns a HeaderError listing the fields without column,
+// which are required by the tag option "required" or ReadConfig.StrictHeaders,
+// and, with ReadConfig.StrictHeaders, the columns without field.
+func checkHeaders(rc *ReadConfig, fields []structField, headers []string, columnToField []int) error {
+	bound := make([]bool, len(fields))
+	he := HeaderError{}
+	for columnIndex, fieldIndex := range columnToField {
+		if fieldIndex >= 0 {
+			bound[fieldIndex] = true
+		} else if rc.StrictHeaders && (headers[columnIndex] != "" || rc.NoHeaderRow) {
+			header := headers[columnIndex]
+			if header == "" {
+				header = xlsx.ColIndexToLetters(columnIndex)
+			}
+			he.Unexpected = append(he.Unexpected, header)
+		}
 	}
+	for i, sf := range fields {
+		if bound[i] || !(rc.StrictHeaders || sf.tag.Has("required")) {
+			continue
+		}
+		header := sf.header
+		if header == "" {
+			if columnIndex, ok, _ := sf.column(); ok {
+				header = xlsx.ColIndexToLetters(columnIndex)
+			} else {
+				header = sf.name
+			}
+		}
+		he.Missing = append(he.Missing, header)
+	}
+	if len(he.Missing) > 0 || len(he.Unexpected) > 0 {
+		return he
+	}
+	return nil
+}
 
-	collectedErrors := make([]FieldError, 0)
+// read unmarshals a data row.
+// It reports false for rows rejected by a filter func, see WithFilter, and rows with errors.
+// With UnmarshalErrorAbort the first FieldError is returned to end reading with.
+// With UnmarshalErrorCollect the errors are collected,
+// the ContentError is returned as soon as MaxUnmarshalErrors is reached.
+func (r *sheetReader[T]) read(row sheetRow) (nT T, ok bool, err error) {
+	rc := r.rc
+	rowIndex := row.index
+	val := reflect.New(r.typ).Elem()
+	rowHasErrors := false
+	for columnIndex, fi := range r.columnFields {
+		// If there is no unmarshal function,
+		// this field has been skipped by previous logic.
+		// e.g. no destination field, or unknown type.
+		if fi.unmarshalFunc == nil {
+			if rc.UnusedColumnsHandler != nil {
+				rc.UnusedColumnsHandler(row.cell(columnIndex), &val, fi)
+			}
+			continue
+		}
+		cell := row.cell(columnIndex)
 
-	ts := make([]T, 0)
-	for rowIndex := 0; rowIndex < sheet.MaxRow; rowIndex++ {
-		if rowIndex >= rc.DataStartRowIndex {
-			val := reflect.New(typ).Elem()
-			if row, _ := sheet.Row(rowIndex); row != nil {
-
-				for columnIndex, fi := range columnFields {
-					// If there is no unmarshal function,
-					// this field has been skipped by previous logic.
-					// e.g. no destination field, or unknown type.
-					if fi.unmarshalFunc == nil {
-						if rc.UnusedColumnsHandler != nil {
-							rc.UnusedColumnsHandler(row.GetCell(columnIndex), &val, fi)
-						}
-						continue
-					}
-					cell := row.GetCell(columnIndex)
-
-					destField := val.Field(fi.reflectFieldIndex)
-					err := fi.unmarshalFunc(destField, cell, unmarshalConfig)
-					if err != nil && rc.UnmarshalErrorHandling != UnmarshalErrorIgnore {
-						if rc.RowUnmarshalErrorHandler != nil {
-							rc.RowUnmarshalErrorHandler(cell, &val, fi)
-							continue
-						}
-						fer := FieldError{
-							RowIndex:     rowIndex,
-							ColumnIndex:  columnIndex,
-							ColumnHeader: fi.Header,
-							Err:          err,
-						}
-						if rc.UnmarshalErrorHandling == UnmarshalErrorAbort {
-							return nil, fer
-						} else {
-							collectedErrors = append(collectedErrors, fer)
-							if rc.MaxUnmarshalErrors > 0 && uint64(len(collectedErrors)) >= rc.MaxUnmarshalErrors {
-								return nil, ContentError{
-									FieldErrors:  collectedErrors,
-									LimitReached: true,
-								}
-							}
-						}
-					}
-				}
-				nT := val.Addr().Interface().(T)
-				add := true
-				if filterFunc != nil && len(filterFunc) > 0 {
-					for _, fF := range filterFunc {
-						if fF != nil {
-							add = fF(nT)
-							if !add {
-								break
-							}
-						}
-					}
-				}
-				if add {
-					ts = append(ts, nT)
+		destField := fieldByIndex(val, fi.reflectFieldIndex)
+		err := fi.unmarshalFunc(destField, cell, r.unmarshalConfig)
+		if err == nil {
+			err = fi.validate(destField, cell, rc.TrimSpace)
+		}
+		if err != nil && rc.UnmarshalErrorHandling != UnmarshalErrorIgnore {
+			if rc.RowUnmarshalErrorHandler != nil {
+				rc.RowUnmarshalErrorHandler(cell, &val, fi)
+				continue
+			}
+			rowHasErrors = true
+			if err := r.collector.add(FieldError{
+				RowIndex:     rowIndex,
+				ColumnIndex:  columnIndex,
+				ColumnHeader: fi.Header,
+				Err:          err,
+			}); err != nil {
+				return nT, false, err
+			}
+		}
+	}
+	if rowHasErrors {
+		return nT, false, nil
+	}
+	nT = val.Addr().Interface().(T)
+	for _, fF := range r.filters {
+		if !fF(nT) {
+			return nT, false, nil
+		}
+	}
+	if rc.UnmarshalErrorHandling != UnmarshalErrorIgnore {
+		// Duplicates are handled like unmarshal errors
+		for _, fer := range r.seen.check(row, val, r.columnFields, rc.TrimSpace) {
+			if rc.RowUnmarshalErrorHandler != nil {
+				rc.RowUnmarshalErrorHandler(row.cell(fer.ColumnIndex), &val, r.columnFields[fer.ColumnIndex])
+				continue
+			}
+			rowHasErrors = true
+			if err := r.collector.add(fer); err != nil {
+				return nT, false, err
+			}
+		}
+	}
+	if validator, ok := any(nT).(RowValidator); ok && rc.UnmarshalErrorHandling != UnmarshalErrorIgnore {
+		if err := validator.ValidateExcelRow(); err != nil {
+			rowHasErrors = true
+			for _, rowErr := range r.rowErrors(rowIndex, err) {
+				if err := r.collector.add(rowErr); err != nil {
+					return nT, false, err
 				}
 			}
 		}
 	}
-	if len(collectedErrors) > 0 {
-		return nil, ContentError{
-			FieldErrors:  collectedErrors,
-			LimitReached: false,
+	return nT, !rowHasErrors, nil
+}
+
+// rowErrors converts the error of RowValidator.ValidateExcelRow.
+// FieldErrors, also when joined with errors.Join, are kept as errors of their column,
+// which is looked up by ColumnHeader. Any other error becomes a RowError.
+func (r *sheetReader[T]) rowErrors(rowIndex int, err error) []error {
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else {
+		errs = []error{err}
+	}
+	converted := make([]error, 0, len(errs))
+	for _, e := range errs {
+		var fer FieldError
+		if !errors.As(e, &fer) {
+			converted = append(converted, RowError{RowIndex: rowIndex, Err: e})
+			continue
+		}
+		fer.RowIndex = rowIndex
+		fer.ColumnIndex = -1
+		for columnIndex, fi := range r.columnFields {
+			if fi.Header == fer.ColumnHeader && fi.unmarshalFunc != nil {
+				fer.ColumnIndex = columnIndex
+				break
+			}
 		}
+		converted = append(converted, fer)
+	}
+	return converted
+}
+
+// errorCollector applies ReadConfig.UnmarshalErrorHandling
+// to the FieldErrors and RowErrors of the data rows.
+type errorCollector struct {
+	rc      *ReadConfig
+	content ContentError
+}
+
+// add records a FieldError or RowError.
+// It returns the error to end reading with, if reading has to be aborted.
+func (c *errorCollector) add(err error) error {
+	if c.rc.UnmarshalErrorHandling == UnmarshalErrorAbort {
+		return err
+	}
+	switch e := err.(type) {
+	case FieldError:
+		c.content.FieldErrors = append(c.content.FieldErrors, e)
+	case RowError:
+		c.content.RowErrors = append(c.content.RowErrors, e)
+	}
+	if c.rc.MaxUnmarshalErrors > 0 && uint64(c.content.count()) >= c.rc.MaxUnmarshalErrors {
+		c.content.LimitReached = true
+		return c.content
+	}
+	return nil
+}
+
+// err returns the collected errors, if any.
+func (c *errorCollector) err() error {
+	if c.content.count() > 0 {
+		return c.content
+	}
+	return nil
+}
+
+// ReadParsed opens an already parsed xlsx file directly.
+// Each row is parsed and unmarshalled into a slice of `T`.
+func ReadParsed[T any](f *xlsx.File, opts ...ReadOption) ([]T, error) {
+	return ReadParsedContext[T](context.Background(), f, opts...)
+}
+
+// ReadParsedContext is ReadParsed with a context, which is checked between rows.
+// Reading ends with the error of the context once it is done,
+// e.g. when the client of an HTTP upload disconnects.
+func ReadParsedContext[T any](ctx context.Context, f *xlsx.File, opts ...ReadOption) ([]T, error) {
+	return collectRows(readSheet[T](ctx, parsedWorkbook(f), opts...))
+}
+
+// collectRows collects the rows of the iterator, up to the first error.
+func collectRows[T any](rows iter.Seq2[T, error]) ([]T, error) {
+	ts := make([]T, 0)
+	for t, err := range rows {
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
 	}
 	return ts, nil
 }
 
 // ReadExcel walk func from excel
-func ReadExcel(file string, sheetIndex int, walk func(index int, rows *xlsx.Row)) error {
+// The cells of each row are passed up to the last column of the sheet.
+func ReadExcel(file string, sheetIndex int, walk func(index int, cells []*Cell)) error {
 	f, err := xlsx.OpenFile(file)
 	if err != nil {
 		return err
 	}
-	sheet := f.Sheets[sheetIndex]
-	for i := 0; i < sheet.MaxRow; i++ {
-		if row, _ := sheet.Row(i); row != nil {
-			walk(i, row)
+	if sheetIndex < 0 || sheetIndex > len(f.Sheets)-1 {
+		return ErrSheetIndexOutOfRange
+	}
+	sheet, err := xlsxWorkbook{file: f}.sheet(sheetIndex)
+	if err != nil {
+		return err
+	}
+	for row, err := range sheet.rows() {
+		if err != nil {
+			return err
 		}
+		walk(row.index, row.cells)
 	}
 	return nil
 }
@@ -13,11 +13,14 @@
 package exl
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -54,7 +57,7 @@ func (t *readTmp) ReadConfigure(rc *ReadConfig) {
 	rc.SheetName = "Sheet1"
 }
 
-func countUnmarshalErrors(cell *xlsx.Cell, val *reflect.Value, fi FieldInfo) {
+func countUnmarshalErrors(cell *Cell, val *reflect.Value, fi FieldInfo) {
 	countF := val.FieldByName("ErrorsCount")
 	countF.SetInt(countF.Int() + 1)
 }
@@ -63,7 +66,7 @@ func (t *readErrorTmp) ReadConfigure(rc *ReadConfig) {
 	rc.RowUnmarshalErrorHandler = countUnmarshalErrors
 }
 
-func countUnusedColumns(cell *xlsx.Cell, val *reflect.Value, fi FieldInfo) {
+func countUnusedColumns(cell *Cell, val *reflect.Value, fi FieldInfo) {
 	countF := val.FieldByName("Count")
 	countF.SetInt(countF.Int() + 1)
 }
@@ -171,7 +174,7 @@ func TestContentErrorUnwrap(t *testing.T) {
 
 type customUnmarshalledString string
 
-func (s *customUnmarshalledString) UnmarshalExcel(cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func (s *customUnmarshalledString) UnmarshalExcel(cell *Cell, params *ExcelUnmarshalParameters) error {
 	if cell.Value == "error please" {
 		return errors.New("excel unmarshalled: unit test error")
 	} else {
@@ -207,13 +210,13 @@ func TestGetUnmarshalFunc(t *testing.T) {
 	// Test cell with a value to be unmarshalled,
 	// using a date value so the time unmarshaler can use this.
 	// Every other unmarshaler will just use the raw string value
-	successfulCell := &xlsx.Cell{
+	successfulCell := &Cell{
 		Value:  "12000",
 		NumFmt: xlsx.DefaultDateTimeFormat,
 	}
 	// Test cell with a specific value which causes the dummy unmarshalers
 	// to explicitly cause errors, and the string unmarshaler to error out due to a formatting issue.
-	errorCell := &xlsx.Cell{
+	errorCell := &Cell{
 		Value:  "error please",
 		NumFmt: "<><><>error<><><>",
 	}
@@ -959,27 +962,27 @@ func TestReadFilterFunc(t *testing.T) {
 		t.Error("test failed: " + err.Error())
 	}
 	{
-		if models, err := ReadFile[*readTmp](testFile, func(t *readTmp) (add bool) {
+		if models, err := ReadFile[*readTmp](testFile, WithFilter(func(t *readTmp) (add bool) {
 			return true
-		}); err != nil {
+		})); err != nil {
 			t.Error("test failed: " + err.Error())
 		} else if len(models) != 2 {
 			t.Error("test failed")
 		}
 	}
 	{
-		if models, err := ReadFile[*readTmp](testFile, func(t *readTmp) (add bool) {
+		if models, err := ReadFile[*readTmp](testFile, WithFilter(func(t *readTmp) (add bool) {
 			return false
-		}); err != nil {
+		})); err != nil {
 			t.Error("test failed: " + err.Error())
 		} else if len(models) != 0 {
 			t.Error("test failed")
 		}
 	}
 	{
-		if models, err := ReadFile[*readTmp](testFile, func(t *readTmp) (add bool) {
+		if models, err := ReadFile[*readTmp](testFile, WithFilter(func(t *readTmp) (add bool) {
 			return t.Name1 == "Name11"
-		}); err != nil {
+		})); err != nil {
 			t.Error("test failed: " + err.Error())
 		} else if len(models) != 1 {
 			t.Error("test failed")
@@ -1003,11 +1006,12 @@ func testBasic(t *testing.T, testNum int) {
 	if err := WriteExcel(testFile, data); err != nil {
 		t.Fatal(err.Error())
 	}
-	if err := ReadExcel(testFile, 0, func(index int, rows *xlsx.Row) {
-		equal(t, fmt.Sprintf("%d", index), rows.GetCell(0).Value)
+	if err := ReadExcel(testFile, 0, func(index int, cells []*Cell) {
+		equal(t, fmt.Sprintf("%d", index), cells[0].Value)
 	}); err != nil {
 		t.Fatal(err.Error())
 	}
+	equal(t, ErrSheetIndexOutOfRange, ReadExcel(testFile, 1, nil))
 }
 
 func TestBasic(t *testing.T) {
@@ -1015,3 +1019,299 @@ func TestBasic(t *testing.T) {
 	testBasic(t, 100)
 	testBasic(t, 10000)
 }
+
+func TestReadNestedStructs(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"ID", "Created By", "Addr.City", "Addr.Zip", "Billing City", "City"},
+		{"1", "alice", "Berlin", "10115", "Hamburg", "Munich"},
+		{"2", "bob", "Paris", "75001", "", ""},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	models, err := ReadFile[*fieldsTmp](testFile)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 2, len(models))
+	equal(t, 1, models[0].ID)
+	equal(t, "alice", models[0].CreatedBy)
+	equal(t, fieldsAddress{City: "Berlin", Zip: "10115"}, models[0].Address)
+	equal(t, &fieldsAddress{City: "Hamburg"}, models[0].Billing)
+	equal(t, fieldsAddress{City: "Munich"}, models[0].Inline)
+	equal(t, "bob", models[1].CreatedBy)
+	equal(t, "Paris", models[1].Address.City)
+}
+
+type readColumnTmp struct {
+	Code  string `excel:"Code"`
+	Name  string `excel:"col=C"`
+	Alt   string `excel:"Name,idx=3"`
+	Price int    `excel:"Price"`
+}
+
+type readPositionTmp struct {
+	Code  string `excel:"Code"`
+	Price int    `excel:""`
+	Name  string `excel:"col=D"`
+}
+
+type readInvalidColumnTmp struct {
+	Name string `excel:"col=1"`
+}
+
+func TestReadColumnBinding(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"Code", "Price", "Name", "Name"},
+		{"A1", "3", "Apple", "Malus"},
+		{"A2", "x", "Pear", "Pyrus"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("by header and column", func(t *testing.T) {
+		models, err := ReadFile[*readColumnTmp](testFile, WithMaxUnmarshalErrors(0), WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readColumnTmp{{"A1", "Apple", "Malus", 3}, {"A2", "Pear", "Pyrus", 0}}, models)
+	})
+	t.Run("without header row", func(t *testing.T) {
+		models, err := ReadFile[*readPositionTmp](testFile, WithNoHeaderRow(), WithDataStartRow(1), WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, []*readPositionTmp{{"A1", 3, "Malus"}}, models[:1])
+
+		_, err = ReadFile[*readPositionTmp](testFile, WithNoHeaderRow(), WithDataStartRow(0))
+		equal(t, "error unmarshalling column \"B\" in row 1: error parsing cell as integer value: strconv.ParseInt: parsing \"Price\": invalid syntax", err.Error())
+	})
+	t.Run("column letters in errors", func(t *testing.T) {
+		_, err := ReadFile[*readOptionTmp](testFile, WithNoHeaderRow(), WithReadConfig(func(rc *ReadConfig) { rc.SkipUnknownColumns = false }))
+		equal(t, "no destination field with matching tag for column \"C\" at index 2", err.Error())
+	})
+	t.Run("invalid column", func(t *testing.T) {
+		_, err := ReadFile[*readInvalidColumnTmp](testFile)
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Error("test failed: expected ErrInvalidTag, got:", err)
+		}
+	})
+}
+
+type readRequiredTmp struct {
+	SKU   string `excel:"SKU,required"`
+	Name  string `excel:"Name"`
+	Price int    `excel:"Price,required"`
+	Stock int    `excel:"col=F,required"`
+}
+
+func TestReadRequiredHeaders(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"Name", "SKU", "Color", "Size"},
+		{"Apple", "A1", "red", "error please"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("required", func(t *testing.T) {
+		_, err := ReadFile[*readRequiredTmp](testFile)
+		equal(t, HeaderError{Missing: []string{"Price", "F"}}, err)
+		equal(t, "missing columns \"Price\", \"F\"", err.Error())
+		if !errors.Is(err, ErrMissingColumns) || errors.Is(err, ErrNoDestinationField) {
+			t.Error("test failed: unexpected errors.Is result")
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		_, err := ReadFile[*readOptionTmp](testFile, WithStrictHeaders())
+		equal(t, HeaderError{Missing: []string{"Name1", "Name2"}, Unexpected: []string{"Name", "SKU", "Color", "Size"}}, err)
+		if !errors.Is(err, ErrMissingColumns) || !errors.Is(err, ErrNoDestinationField) {
+			t.Error("test failed: unexpected errors.Is result")
+		}
+	})
+	t.Run("strict fails before data rows", func(t *testing.T) {
+		_, err := ReadFile[*abortUnmarshalErrors](testFile, WithStrictHeaders())
+		equal(t, "missing columns \"Name1\", unexpected columns \"Name\", \"SKU\", \"Color\", \"Size\"", err.Error())
+	})
+	t.Run("strict without header row", func(t *testing.T) {
+		models, err := ReadFile[*readTmp](testFile, WithStrictHeaders(), WithNoHeaderRow(), WithDataStartRow(1))
+		equal(t, HeaderError{Missing: []string{"Name5"}}, err)
+		equal(t, []*readTmp(nil), models)
+	})
+}
+
+type readRowValidatorTmp struct {
+	Name  string `excel:"Name"`
+	Start int    `excel:"Start"`
+	End   int    `excel:"End"`
+}
+
+func (r *readRowValidatorTmp) ValidateExcelRow() error {
+	switch {
+	case r.Name == "":
+		return errors.New("name is missing")
+	case r.End < r.Start:
+		return errors.Join(
+			FieldError{ColumnHeader: "End", Err: errors.New("end before start")},
+			errors.New("range is invalid"),
+		)
+	}
+	return nil
+}
+
+func TestReadRowValidator(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"Name", "Start", "End"},
+		{"ok", "1", "2"},
+		{"", "1", "2"},
+		{"range", "3", "2"},
+		{"cell", "x", "2"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("collect", func(t *testing.T) {
+		_, err := ReadFile[*readRowValidatorTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+		equal(t, ContentError{
+			FieldErrors: []FieldError{
+				{RowIndex: 3, ColumnIndex: 2, ColumnHeader: "End", Err: errors.New("end before start")},
+				{RowIndex: 4, ColumnIndex: 1, ColumnHeader: "Start", Err: err.(ContentError).FieldErrors[1].Err},
+			},
+			RowErrors: []RowError{
+				{RowIndex: 2, Err: errors.New("name is missing")},
+				{RowIndex: 3, Err: errors.New("range is invalid")},
+			},
+		}, err)
+		equal(t, "4 errors reading data from Excel", err.Error())
+		equal(t, 4, len(err.(ContentError).Unwrap()))
+	})
+	t.Run("abort", func(t *testing.T) {
+		_, err := ReadFile[*readRowValidatorTmp](testFile)
+		equal(t, RowError{RowIndex: 2, Err: errors.New("name is missing")}, err)
+		equal(t, "error validating row 3: name is missing", err.Error())
+	})
+	t.Run("limit", func(t *testing.T) {
+		_, err := ReadFile[*readRowValidatorTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect), WithMaxUnmarshalErrors(2))
+		equal(t, "too many (2) errors reading data from Excel", err.Error())
+	})
+	t.Run("ignore", func(t *testing.T) {
+		models, err := ReadFile[*readRowValidatorTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, 4, len(models))
+	})
+	t.Run("filtered rows are not validated", func(t *testing.T) {
+		_, err := ReadFile[*readRowValidatorTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect),
+			WithFilter(func(r *readRowValidatorTmp) bool { return r.Name == "ok" }))
+		equal(t, 1, len(err.(ContentError).FieldErrors))
+		equal(t, 0, len(err.(ContentError).RowErrors))
+	})
+}
+
+type readCNY int64
+
+type readUnmarshalFuncsTmp struct {
+	Price    readCNY  `excel:"Price"`
+	Discount *readCNY `excel:"Discount"`
+	Note     string   `excel:"Note"`
+	Amount   int64    `excel:"Amount"`
+}
+
+func TestReadUnmarshalFuncs(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	data := [][]string{
+		{"Price", "Discount", "Note", "Amount"},
+		{"¥12.50", "¥0.5", "note", "3"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	unmarshalCNY := func(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
+		f, err := strconv.ParseFloat(strings.TrimPrefix(cell.Value, "¥"), 64)
+		if err != nil {
+			return err
+		}
+		destValue.SetInt(int64(math.Round(f * 100)))
+		return nil
+	}
+	unmarshalNote := func(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
+		destValue.SetString(strings.ToUpper(cell.Value))
+		return nil
+	}
+
+	// Without the funcs, the default int unmarshaler fails
+	if _, err := ReadFile[*readUnmarshalFuncsTmp](testFile); err == nil {
+		t.Error("test failed: expected error")
+	}
+
+	models, err := ReadFile[*readUnmarshalFuncsTmp](testFile,
+		WithUnmarshalFunc[readCNY](unmarshalCNY),
+		WithHeaderUnmarshalFunc("Note", unmarshalNote),
+	)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	discount := readCNY(50)
+	equal(t, []*readUnmarshalFuncsTmp{{Price: 1250, Discount: &discount, Note: "NOTE", Amount: 3}}, models)
+
+	// The header func takes precedence over the type func
+	models, err = ReadFile[*readUnmarshalFuncsTmp](testFile, WithReadConfig(func(rc *ReadConfig) {
+		rc.UnmarshalFuncs = map[reflect.Type]UnmarshalExcelFunc{reflect.TypeFor[readCNY](): unmarshalCNY}
+		rc.HeaderUnmarshalFuncs = map[string]UnmarshalExcelFunc{
+			"Price": func(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
+				destValue.SetInt(1)
+				return nil
+			},
+		}
+	}))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, readCNY(1), models[0].Price)
+	equal(t, readCNY(50), *models[0].Discount)
+}
+
+func TestReadParsedContext(t *testing.T) {
+	f := xlsx.NewFile()
+	sheet, _ := f.AddSheet("Sheet1")
+	sheet.AddRow().AddCell().SetString("Name1")
+	for _, name := range []string{"a", "b", "c"} {
+		sheet.AddRow().AddCell().SetString(name)
+	}
+
+	t.Run("progress", func(t *testing.T) {
+		var progress [][2]int
+		models, err := ReadParsedContext[*readTmp](context.Background(), f, WithProgress(func(processed, total int) {
+			progress = append(progress, [2]int{processed, total})
+		}))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, 3, len(models))
+		equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, progress)
+	})
+
+	t.Run("canceled between rows", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		models, err := ReadParsedContext[*readTmp](ctx, f, WithProgress(func(processed, total int) {
+			if processed == 2 {
+				cancel()
+			}
+		}))
+		equal(t, context.Canceled, err)
+		equal(t, 0, len(models))
+	})
+}
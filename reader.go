@@ -10,3 +10,54 @@
 // limitations under the License.
 
 package exl
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// Rows returns an iterator over the rows of an already parsed xlsx file.
+// Each row is unmarshalled into a `T` and yielded as soon as it has been read,
+// so the caller does not have to keep the whole sheet in memory as `[]T`.
+// Header mapping, configuration and error handling are the same as for ReadParsed.
+// An error ends the iteration, except that with UnmarshalErrorCollect
+// the rows without errors are yielded first, and the collected errors last.
+func Rows[T any](f *xlsx.File, opts ...ReadOption) iter.Seq2[T, error] {
+	return readSheet[T](context.Background(), parsedWorkbook(f), opts...)
+}
+
+// RowsReaderAt opens an xlsx file from the given io.ReaderAt
+// and returns an iterator over its rows, see Rows.
+// The file is opened when the iteration starts.
+func RowsReaderAt[T any](reader io.ReaderAt, size int64, opts ...ReadOption) iter.Seq2[T, error] {
+	return rowsOpened[T](func() (*xlsx.File, error) { return xlsx.OpenReaderAt(reader, size) }, opts...)
+}
+
+// RowsFile opens an xlsx file at the given file path
+// and returns an iterator over its rows, see Rows.
+// The file is opened when the iteration starts.
+func RowsFile[T any](file string, opts ...ReadOption) iter.Seq2[T, error] {
+	return rowsOpened[T](func() (*xlsx.File, error) { return xlsx.OpenFile(file) }, opts...)
+}
+
+// RowsBinary opens an xlsx file from the provided bytes
+// and returns an iterator over its rows, see Rows.
+// The file is opened when the iteration starts.
+func RowsBinary[T any](bytes []byte, opts ...ReadOption) iter.Seq2[T, error] {
+	return rowsOpened[T](func() (*xlsx.File, error) { return xlsx.OpenBinary(bytes) }, opts...)
+}
+
+func rowsOpened[T any](open func() (*xlsx.File, error), opts ...ReadOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		f, err := open()
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		Rows[T](f, opts...)(yield)
+	}
+}
@@ -10,3 +10,145 @@
 // limitations under the License.
 
 package exl
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"Name1", "Name2", "Name3", "Name4", "Name5"},
+		{"Name11", "Name22", "Name33", "Name44", "Name55"},
+		{"Name111", "Name222", "Name333", "Name444", "Name555"},
+		{"Name1111", "Name2222", "Name3333", "Name4444", "Name5555"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("all rows", func(t *testing.T) {
+		i := 0
+		for m, err := range RowsFile[*readTmp](testFile) {
+			if err != nil {
+				t.Fatal("test failed: " + err.Error())
+			}
+			i++
+			equal(t, data[i][0], m.Name1)
+			equal(t, data[i][4], m.Name5)
+		}
+		equal(t, len(data)-1, i)
+	})
+	t.Run("stop early", func(t *testing.T) {
+		i := 0
+		for range RowsFile[*readTmp](testFile) {
+			i++
+			if i == 2 {
+				break
+			}
+		}
+		equal(t, 2, i)
+	})
+	t.Run("filter", func(t *testing.T) {
+		var names []string
+		for m, err := range RowsFile[*readTmp](testFile, WithFilter(func(t *readTmp) (add bool) { return t.Name1 != "Name111" })) {
+			if err != nil {
+				t.Fatal("test failed: " + err.Error())
+			}
+			names = append(names, m.Name1)
+		}
+		equal(t, []string{"Name11", "Name1111"}, names)
+	})
+	t.Run("binary and reader at", func(t *testing.T) {
+		bytes, err := os.ReadFile(testFile)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		i := 0
+		for _, err := range RowsBinary[*readTmp](bytes) {
+			if err != nil {
+				t.Fatal("test failed: " + err.Error())
+			}
+			i++
+		}
+		equal(t, len(data)-1, i)
+
+		file, err := os.Open(testFile)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		defer file.Close()
+		i = 0
+		for _, err := range RowsReaderAt[*readTmp](file, int64(len(bytes))) {
+			if err != nil {
+				t.Fatal("test failed: " + err.Error())
+			}
+			i++
+		}
+		equal(t, len(data)-1, i)
+	})
+	t.Run("open error", func(t *testing.T) {
+		for _, err := range RowsBinary[*readTmp](nil) {
+			equal(t, "zip: not a valid zip file", err.Error())
+		}
+	})
+	t.Run("config error", func(t *testing.T) {
+		for _, err := range RowsFile[*readSheetIndexOutOfRange](testFile) {
+			equal(t, ErrSheetIndexOutOfRange, err)
+		}
+	})
+}
+
+func TestRowsUnmarshalErrors(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"Name1"},
+		{"ok"},
+		{"error please"},
+		{"ok"},
+		{"error please"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		rows, errs := 0, 0
+		for _, err := range RowsFile[*abortUnmarshalErrors](testFile) {
+			if err != nil {
+				errs++
+				var fer FieldError
+				if !errors.As(err, &fer) {
+					t.Error("test failed: expected FieldError, got:", err)
+				}
+				equal(t, 2, fer.RowIndex)
+			} else {
+				rows++
+			}
+		}
+		equal(t, 1, rows)
+		equal(t, 1, errs)
+	})
+	t.Run("collect", func(t *testing.T) {
+		var names []customUnmarshalledString
+		var last error
+		for m, err := range RowsFile[*collectUnmarshalErrorsUnlimited](testFile) {
+			if err != nil {
+				last = err
+			} else {
+				names = append(names, m.Name1)
+			}
+		}
+		equal(t, []customUnmarshalledString{"excel unmarshalled: ok", "excel unmarshalled: ok"}, names)
+		var cer ContentError
+		if !errors.As(last, &cer) {
+			t.Fatal("test failed: expected ContentError, got:", last)
+		}
+		equal(t, 2, len(cer.FieldErrors))
+	})
+}
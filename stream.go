@@ -0,0 +1,571 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// streamMaxRows is the number of rows of a sheet, including the header row,
+// after which WriteStream continues in a new sheet.
+var streamMaxRows = 1048576
+
+const (
+	spreadsheetNamespace         = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	officeRelationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+type (
+	// streamWriter writes the sheets of a row type directly into the zip archive,
+	// so only the current row is held in memory.
+	streamWriter struct {
+		zw         *zip.Writer
+		wc         *WriteConfig
+		columns    []writeColumn
+		styles     *streamStyles
+		sheetNames []string
+		tables     int
+		tableNames map[string]bool
+		// List formulas of the dropdowns by column, and the hidden sheets listing their values
+		dropdowns []string
+		lookups   []streamLookup
+		// Current sheet
+		out      *bufio.Writer
+		rowIndex int
+		totals   *columnTotals
+	}
+	// streamLookup is a hidden sheet listing dropdown values, one list per column.
+	streamLookup struct {
+		name    string
+		columns [][]string
+	}
+	// streamStyles collects the distinct cell styles, written as styles part at the end.
+	streamStyles struct {
+		index  map[CellStyle]int
+		styles []CellStyle
+	}
+)
+
+// WriteStream writes the rows to the excel file without holding them in memory,
+// for exports too large for Write.
+// A sheet holds at most 1,048,576 rows, further rows continue in new sheets
+// named like "Sheet1 (2)".
+// WriteConfig.AutoFitWidth is not supported, as the widths are written before the rows.
+// WriteConfig.Progress is called with the total -1, as the number of rows is not known in advance.
+func WriteStream[T WriteConfigurator](file string, rows iter.Seq[T]) error {
+	target, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = WriteStreamTo(target, rows); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// WriteStreamTo writes the rows to w, see WriteStream.
+func WriteStreamTo[T WriteConfigurator](w io.Writer, rows iter.Seq[T]) error {
+	wc := defaultWriteConfig()
+	tT := new(T)
+	(*tT).WriteConfigure(wc)
+	columns, err := writeColumns(reflect.TypeOf(tT).Elem().Elem(), wc)
+	if err != nil {
+		return err
+	}
+	sw := &streamWriter{
+		zw:         zip.NewWriter(w),
+		wc:         wc,
+		columns:    columns,
+		styles:     &streamStyles{index: make(map[CellStyle]int)},
+		tableNames: make(map[string]bool),
+	}
+	sw.addDropdowns()
+	processed := 0
+	for t := range rows {
+		if err = sw.writeRow(reflect.ValueOf(t).Elem()); err != nil {
+			return err
+		}
+		processed++
+		if wc.Progress != nil {
+			wc.Progress(processed, -1)
+		}
+	}
+	return sw.close()
+}
+
+// WriteChan writes the rows received from the channel to the excel file, see WriteStream.
+// It returns when the channel is closed, or as soon as writing fails.
+// Then a goroutine receives and discards the remaining rows until the channel is closed,
+// so the sending goroutine does not block forever; it has to close the channel in any case.
+func WriteChan[T WriteConfigurator](file string, rows <-chan T) error {
+	err := WriteStream(file, chanSeq(rows))
+	if err != nil {
+		go drainChan(rows)
+	}
+	return err
+}
+
+// WriteChanTo writes the rows received from the channel to w, see WriteChan.
+func WriteChanTo[T WriteConfigurator](w io.Writer, rows <-chan T) error {
+	err := WriteStreamTo(w, chanSeq(rows))
+	if err != nil {
+		go drainChan(rows)
+	}
+	return err
+}
+
+// drainChan receives and discards the values of the channel until it is closed.
+func drainChan[T any](ch <-chan T) {
+	for range ch {
+	}
+}
+
+func chanSeq[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for t := range ch {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+func (sw *streamWriter) writeRow(value reflect.Value) error {
+	maxRows := streamMaxRows
+	if sw.wc.TotalsRow {
+		// Keep a row for the totals
+		maxRows--
+	}
+	if sw.out == nil || sw.rowIndex >= maxRows {
+		if err := sw.startSheet(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(sw.out, `<row r="%d">`, sw.rowIndex+1)
+	cells, err := marshalRow(sw.columns, sw.rowIndex, value)
+	if err != nil {
+		return err
+	}
+	for columnIndex, cell := range cells {
+		sw.writeCell(columnIndex, cell, sw.columns[columnIndex].style)
+		sw.totals.add(columnIndex, cell)
+	}
+	sw.out.WriteString(`</row>`)
+	sw.rowIndex++
+	return nil
+}
+
+func (sw *streamWriter) writeCell(columnIndex int, cell *Cell, cs CellStyle) {
+	if cs.Format == "" && cell.NumFmt != "general" {
+		cs.Format = cell.NumFmt
+	}
+	s := sw.styles.add(cs)
+	if cell.Value == "" && cell.Formula() == "" && s == 0 {
+		return
+	}
+	fmt.Fprintf(sw.out, `<c r="%s"`, xlsx.GetCellIDStringFromCoords(columnIndex, sw.rowIndex))
+	if s > 0 {
+		fmt.Fprintf(sw.out, ` s="%d"`, s)
+	}
+	switch {
+	case cell.Formula() != "":
+		sw.out.WriteString(`><f>`)
+		_ = xml.EscapeText(sw.out, []byte(cell.Formula()))
+		sw.out.WriteString(`</f>`)
+		if cell.Value != "" {
+			sw.out.WriteString(`<v>`)
+			_ = xml.EscapeText(sw.out, []byte(cell.Value))
+			sw.out.WriteString(`</v>`)
+		}
+		sw.out.WriteString(`</c>`)
+	case cell.Type() == CellTypeNumeric || cell.Type() == CellTypeDate:
+		sw.out.WriteString(`><v>`)
+		_ = xml.EscapeText(sw.out, []byte(cell.Value))
+		sw.out.WriteString(`</v></c>`)
+	case cell.Type() == CellTypeBool:
+		fmt.Fprintf(sw.out, ` t="b"><v>%s</v></c>`, cell.Value)
+	default:
+		sw.out.WriteString(` t="inlineStr"><is><t xml:space="preserve">`)
+		_ = xml.EscapeText(sw.out, []byte(cell.Value))
+		sw.out.WriteString(`</t></is></c>`)
+	}
+}
+
+// startSheet ends the current sheet, if any, and starts the next one with the header row.
+func (sw *streamWriter) startSheet() error {
+	if err := sw.endSheet(); err != nil {
+		return err
+	}
+	name := sw.wc.SheetName
+	if n := len(sw.sheetNames) + 1; n > 1 {
+		suffix := fmt.Sprintf(" (%d)", n)
+		// Sheet names are limited to 31 characters
+		if runes := []rune(name); len(runes)+len(suffix) > 31 {
+			name = string(runes[:31-len(suffix)])
+		}
+		name += suffix
+	}
+	sw.sheetNames = append(sw.sheetNames, name)
+	w, err := sw.zw.Create(sheetPart(len(sw.sheetNames) - 1))
+	if err != nil {
+		return err
+	}
+	sw.out = bufio.NewWriter(w)
+	sw.rowIndex = 0
+
+	sw.out.WriteString(xml.Header)
+	fmt.Fprintf(sw.out, `<worksheet xmlns="%s" xmlns:r="%s">`, spreadsheetNamespace, officeRelationshipsNamespace)
+	sw.out.WriteString(`<sheetViews><sheetView workbookViewId="0"`)
+	if len(sw.sheetNames) == 1 {
+		sw.out.WriteString(` tabSelected="1"`)
+	}
+	if sw.wc.FreezeHeader {
+		sw.out.WriteString(`><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/><selection pane="bottomLeft" activeCell="A2" sqref="A2"/></sheetView></sheetViews>`)
+	} else {
+		sw.out.WriteString(`/></sheetViews>`)
+	}
+	cols := false
+	for columnIndex, column := range sw.columns {
+		if column.width > 0 {
+			if !cols {
+				sw.out.WriteString(`<cols>`)
+				cols = true
+			}
+			fmt.Fprintf(sw.out, `<col min="%d" max="%d" width="%s" customWidth="1"/>`,
+				columnIndex+1, columnIndex+1, strconv.FormatFloat(column.width, 'f', -1, 64))
+		}
+	}
+	if cols {
+		sw.out.WriteString(`</cols>`)
+	}
+	sw.out.WriteString(`<sheetData><row r="1">`)
+	for columnIndex, column := range sw.columns {
+		cell := &Cell{}
+		cell.SetString(column.header)
+		sw.writeCell(columnIndex, cell, sw.wc.HeaderStyle)
+	}
+	sw.out.WriteString(`</row>`)
+	sw.rowIndex = 1
+	sw.totals = newColumnTotals(sw.columns, sw.wc)
+	return nil
+}
+
+// endSheet completes the current sheet, if any, and writes its table.
+func (sw *streamWriter) endSheet() error {
+	if sw.out == nil {
+		return nil
+	}
+	ref := "A1:" + xlsx.GetCellIDStringFromCoords(max(len(sw.columns)-1, 0), max(sw.rowIndex-1, 1))
+	if sw.totals != nil {
+		fmt.Fprintf(sw.out, `<row r="%d">`, sw.rowIndex+1)
+		for columnIndex := range sw.columns {
+			cell := &Cell{}
+			sw.totals.cell(cell, columnIndex, 1, sw.rowIndex-1)
+			sw.writeCell(columnIndex, cell, sw.totals.styles[columnIndex])
+		}
+		sw.out.WriteString(`</row>`)
+	}
+	sw.out.WriteString(`</sheetData>`)
+	var table *tableSpec
+	if len(sw.columns) > 0 {
+		if sw.wc.Table {
+			headers := make([]string, len(sw.columns))
+			for i, column := range sw.columns {
+				headers[i] = column.header
+			}
+			table = &tableSpec{name: sw.wc.TableName, style: sw.wc.TableStyle, ref: ref, columns: tableColumns(headers)}
+		} else if sw.wc.AutoFilter {
+			fmt.Fprintf(sw.out, `<autoFilter ref="%s"/>`, ref)
+		}
+	}
+	sw.writeDropdowns()
+	if table != nil {
+		sw.out.WriteString(`<tableParts count="1"><tablePart r:id="rId1"/></tableParts>`)
+	}
+	sw.out.WriteString(`</worksheet>`)
+	if err := sw.out.Flush(); err != nil {
+		return err
+	}
+	sw.out = nil
+	if table == nil {
+		return nil
+	}
+
+	sw.tables++
+	part := fmt.Sprintf("xl/tables/table%d.xml", sw.tables)
+	if err := sw.writePart(part, table.xml(sw.tables, uniqueTableName(table.name, sw.tables, sw.tableNames))); err != nil {
+		return err
+	}
+	rels := fmt.Sprintf(`<Relationships xmlns="%s"><Relationship Id="rId1" Type="%stable" Target="../tables/table%d.xml"/></Relationships>`,
+		relationshipsNamespace, relationshipTypePrefix, sw.tables)
+	return sw.writePart(fmt.Sprintf("xl/worksheets/_rels/sheet%d.xml.rels", len(sw.sheetNames)), xml.Header+rels)
+}
+
+func (sw *streamWriter) writePart(name, content string) error {
+	w, err := sw.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+// close writes the remaining parts, which depend on the sheets and styles written.
+func (sw *streamWriter) close() error {
+	if sw.out == nil {
+		// Always write the header, even if there are no rows
+		if err := sw.startSheet(); err != nil {
+			return err
+		}
+	}
+	if err := sw.endSheet(); err != nil {
+		return err
+	}
+
+	for i, lookup := range sw.lookups {
+		if err := sw.writePart(sheetPart(len(sw.sheetNames)+i), lookup.xml()); err != nil {
+			return err
+		}
+	}
+
+	contentTypes := &strings.Builder{}
+	workbook := &strings.Builder{}
+	workbookRels := &strings.Builder{}
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	fmt.Fprintf(workbook, `%s<workbook xmlns="%s" xmlns:r="%s"><bookViews><workbookView activeTab="0"/></bookViews><sheets>`,
+		xml.Header, spreadsheetNamespace, officeRelationshipsNamespace)
+	fmt.Fprintf(workbookRels, `%s<Relationships xmlns="%s">`, xml.Header, relationshipsNamespace)
+	for i, name := range sw.sheetNames {
+		fmt.Fprintf(contentTypes, `<Override PartName="/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, sheetPart(i))
+		fmt.Fprintf(workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlAttr(name), i+1, i+1)
+		fmt.Fprintf(workbookRels, `<Relationship Id="rId%d" Type="%sworksheet" Target="worksheets/sheet%d.xml"/>`, i+1, relationshipTypePrefix, i+1)
+	}
+	for i, lookup := range sw.lookups {
+		sheetIndex := len(sw.sheetNames) + i
+		fmt.Fprintf(contentTypes, `<Override PartName="/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, sheetPart(sheetIndex))
+		fmt.Fprintf(workbook, `<sheet name="%s" sheetId="%d" state="hidden" r:id="rId%d"/>`, xmlAttr(lookup.name), sheetIndex+1, sheetIndex+1)
+		fmt.Fprintf(workbookRels, `<Relationship Id="rId%d" Type="%sworksheet" Target="worksheets/sheet%d.xml"/>`, sheetIndex+1, relationshipTypePrefix, sheetIndex+1)
+	}
+	for i := 1; i <= sw.tables; i++ {
+		fmt.Fprintf(contentTypes, `<Override PartName="/xl/tables/table%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.table+xml"/>`, i)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(workbookRels, `<Relationship Id="rId%d" Type="%sstyles" Target="styles.xml"/></Relationships>`, len(sw.sheetNames)+len(sw.lookups)+1, relationshipTypePrefix)
+
+	parts := []struct{ name, content string }{
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", sw.styles.xml()},
+		{"_rels/.rels", fmt.Sprintf(`%s<Relationships xmlns="%s"><Relationship Id="rId1" Type="%sofficeDocument" Target="xl/workbook.xml"/></Relationships>`,
+			xml.Header, relationshipsNamespace, relationshipTypePrefix)},
+		{"[Content_Types].xml", contentTypes.String()},
+	}
+	for _, part := range parts {
+		if err := sw.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+	return sw.zw.Close()
+}
+
+// addDropdowns sets the list formulas of the columns with an enum,
+// collecting the values to list in lookup sheets.
+func (sw *streamWriter) addDropdowns() {
+	sw.dropdowns = make([]string, len(sw.columns))
+	for columnIndex, column := range sw.columns {
+		if column.enum == nil {
+			continue
+		}
+		if column.list == "" {
+			sw.dropdowns[columnIndex] = `"` + strings.Join(column.enum, ",") + `"`
+			continue
+		}
+		i := slices.IndexFunc(sw.lookups, func(l streamLookup) bool { return l.name == column.list })
+		if i < 0 {
+			sw.lookups = append(sw.lookups, streamLookup{name: column.list})
+			i = len(sw.lookups) - 1
+		}
+		lookup := &sw.lookups[i]
+		lookupIndex := slices.IndexFunc(lookup.columns, func(values []string) bool { return slices.Equal(values, column.enum) })
+		if lookupIndex < 0 {
+			lookup.columns = append(lookup.columns, column.enum)
+			lookupIndex = len(lookup.columns) - 1
+		}
+		sw.dropdowns[columnIndex] = quoteSheetName(column.list) + "!" +
+			xlsx.GetCellIDStringFromCoordsWithFixed(lookupIndex, 0, true, true) + ":" +
+			xlsx.GetCellIDStringFromCoordsWithFixed(lookupIndex, len(column.enum)-1, true, true)
+	}
+}
+
+// writeDropdowns writes the dropdowns of the data rows of the current sheet.
+func (sw *streamWriter) writeDropdowns() {
+	count := 0
+	for _, formula := range sw.dropdowns {
+		if formula != "" {
+			count++
+		}
+	}
+	if count == 0 {
+		return
+	}
+	fmt.Fprintf(sw.out, `<dataValidations count="%d">`, count)
+	for columnIndex, formula := range sw.dropdowns {
+		if formula == "" {
+			continue
+		}
+		letters := xlsx.ColIndexToLetters(columnIndex)
+		fmt.Fprintf(sw.out, `<dataValidation type="list" allowBlank="1" showErrorMessage="1" error="%s" sqref="%s2:%s%d"><formula1>%s</formula1></dataValidation>`,
+			xmlAttr(enumMessage(sw.columns[columnIndex].enum)), letters, letters, xlsx.Excel2006MaxRowCount, xmlAttr(formula))
+	}
+	sw.out.WriteString(`</dataValidations>`)
+}
+
+// xml returns the worksheet part of the lookup sheet.
+func (l streamLookup) xml() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `%s<worksheet xmlns="%s"><sheetData>`, xml.Header, spreadsheetNamespace)
+	for rowIndex := 0; slices.ContainsFunc(l.columns, func(values []string) bool { return rowIndex < len(values) }); rowIndex++ {
+		fmt.Fprintf(b, `<row r="%d">`, rowIndex+1)
+		for columnIndex, values := range l.columns {
+			if rowIndex < len(values) {
+				fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+					xlsx.GetCellIDStringFromCoords(columnIndex, rowIndex), xmlAttr(values[rowIndex]))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// xmlAttr escapes the value for an XML attribute.
+func xmlAttr(value string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(value))
+	return b.String()
+}
+
+// add returns the index of the cell format of the style, 0 for the default format.
+func (ss *streamStyles) add(cs CellStyle) int {
+	if cs == (CellStyle{}) {
+		return 0
+	}
+	i, ok := ss.index[cs]
+	if !ok {
+		ss.styles = append(ss.styles, cs)
+		i = len(ss.styles)
+		ss.index[cs] = i
+	}
+	return i
+}
+
+// xml returns the styles part with a cell format for each style, after the default format.
+func (ss *streamStyles) xml() string {
+	numFmts, fonts, fills := &strings.Builder{}, &strings.Builder{}, &strings.Builder{}
+	xfs := &strings.Builder{}
+	numFmtIDs := make(map[string]int)
+	fontIDs := map[[2]string]int{{"", ""}: 0}
+	fillIDs := map[string]int{"": 0}
+	fonts.WriteString(`<font><sz val="11"/><name val="Calibri"/></font>`)
+	fills.WriteString(`<fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`)
+	xfs.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`)
+	for _, cs := range ss.styles {
+		numFmtID := 0
+		if cs.Format != "" {
+			if numFmtID = numFmtIDs[cs.Format]; numFmtID == 0 {
+				// Custom formats start after the built-in ones
+				numFmtID = 164 + len(numFmtIDs)
+				numFmtIDs[cs.Format] = numFmtID
+				fmt.Fprintf(numFmts, `<numFmt numFmtId="%d" formatCode="%s"/>`, numFmtID, xmlAttr(cs.Format))
+			}
+		}
+		bold := ""
+		if cs.Bold {
+			bold = "b"
+		}
+		fontID, ok := fontIDs[[2]string{bold, cs.FontColor}]
+		if !ok {
+			fontID = len(fontIDs)
+			fontIDs[[2]string{bold, cs.FontColor}] = fontID
+			fonts.WriteString(`<font>`)
+			if cs.Bold {
+				fonts.WriteString(`<b/>`)
+			}
+			fonts.WriteString(`<sz val="11"/>`)
+			if cs.FontColor != "" {
+				fmt.Fprintf(fonts, `<color rgb="%s"/>`, xmlAttr(cs.FontColor))
+			}
+			fonts.WriteString(`<name val="Calibri"/></font>`)
+		}
+		fillID, ok := fillIDs[cs.FillColor]
+		if !ok {
+			// The first two fills are reserved
+			fillID = len(fillIDs) + 1
+			fillIDs[cs.FillColor] = fillID
+			fmt.Fprintf(fills, `<fill><patternFill patternType="solid"><fgColor rgb="%s"/><bgColor rgb="%s"/></patternFill></fill>`,
+				xmlAttr(cs.FillColor), xmlAttr(cs.FillColor))
+		}
+		fmt.Fprintf(xfs, `<xf numFmtId="%d" fontId="%d" fillId="%d" borderId="0" xfId="0"`, numFmtID, fontID, fillID)
+		if numFmtID > 0 {
+			xfs.WriteString(` applyNumberFormat="1"`)
+		}
+		if fontID > 0 {
+			xfs.WriteString(` applyFont="1"`)
+		}
+		if fillID > 0 {
+			xfs.WriteString(` applyFill="1"`)
+		}
+		if cs.Align != "" || cs.Wrap {
+			xfs.WriteString(` applyAlignment="1"><alignment`)
+			if cs.Align != "" {
+				fmt.Fprintf(xfs, ` horizontal="%s"`, xmlAttr(cs.Align))
+			}
+			if cs.Wrap {
+				xfs.WriteString(` wrapText="1"`)
+			}
+			xfs.WriteString(`/></xf>`)
+		} else {
+			xfs.WriteString(`/>`)
+		}
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `%s<styleSheet xmlns="%s">`, xml.Header, spreadsheetNamespace)
+	if len(numFmtIDs) > 0 {
+		fmt.Fprintf(b, `<numFmts count="%d">%s</numFmts>`, len(numFmtIDs), numFmts)
+	}
+	fmt.Fprintf(b, `<fonts count="%d">%s</fonts>`, len(fontIDs), fonts)
+	fmt.Fprintf(b, `<fills count="%d">%s</fills>`, len(fillIDs)+1, fills)
+	b.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	fmt.Fprintf(b, `<cellXfs count="%d">%s</cellXfs>`, len(ss.styles)+1, xfs)
+	b.WriteString(`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+	return b.String()
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type writeStreamTmp struct {
+	ID      int       `excel:"ID,width=8"`
+	Name    string    `excel:"Name,wrap"`
+	Amount  float64   `excel:"Amount,format=0.00,align=right"`
+	Active  bool      `excel:"Active"`
+	Created time.Time `excel:"Created"`
+	Note    *string   `excel:"Note"`
+}
+
+func (*writeStreamTmp) WriteConfigure(wc *WriteConfig) {
+	wc.SheetName = "Audit"
+	wc.HeaderStyle = CellStyle{Bold: true, FillColor: "FFD9D9D9"}
+	wc.FreezeHeader = true
+	wc.AutoFilter = true
+}
+
+func (*writeStreamTmp) ReadConfigure(_ *ReadConfig) {}
+
+func TestWriteStream(t *testing.T) {
+	created := time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC)
+	note := "a <note> & more"
+	data := []*writeStreamTmp{
+		{1, "apple", 1.5, true, created, &note},
+		{2, "pear", 0.25, false, created, nil},
+		{3, "", 0, false, created, nil},
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteStreamTo(buf, slices.Values(data)); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	models, err := ReadBinary[*writeStreamTmp](buf.Bytes(), WithSheetName("Audit"))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	for i, m := range models {
+		// Empty cells are read into allocated pointers
+		if data[i].Note == nil {
+			equal(t, "", *m.Note)
+			m.Note = nil
+		}
+		equal(t, *data[i], *m)
+	}
+
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheet["Audit"]
+	cell := func(row, col int) *xlsx.Cell {
+		c, _ := sheet.Cell(row, col)
+		return c
+	}
+	equal(t, "ID", cell(0, 0).Value)
+	equal(t, true, cell(0, 0).GetStyle().Font.Bold)
+	equal(t, "FFD9D9D9", cell(0, 5).GetStyle().Fill.FgColor)
+	equal(t, "0.00", cell(1, 2).NumFmt)
+	equal(t, "right", cell(1, 2).GetStyle().Alignment.Horizontal)
+	equal(t, true, cell(1, 1).GetStyle().Alignment.WrapText)
+	equal(t, true, cell(1, 4).IsTime())
+	equal(t, 8.0, *sheet.Col(0).Width)
+
+	parts := zipParts(t, buf.Bytes())
+	sheetXML := parts["xl/worksheets/sheet1.xml"]
+	for _, s := range []string{`state="frozen"`, `<autoFilter ref="A1:F4"/>`, `t="inlineStr"`} {
+		if !strings.Contains(sheetXML, s) {
+			t.Error("test failed: sheet misses " + s)
+		}
+	}
+}
+
+func TestWriteStreamRollOver(t *testing.T) {
+	defer func(maxRows int) { streamMaxRows = maxRows }(streamMaxRows)
+	streamMaxRows = 3
+
+	rows := make(chan *writeTableTmp)
+	go func() {
+		for i := 1; i <= 5; i++ {
+			rows <- &writeTableTmp{ID: i, Name: "n"}
+		}
+		close(rows)
+	}()
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := WriteChan(testFile, rows); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenFile(testFile)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []string{"Sheet1", "Sheet1 (2)", "Sheet1 (3)"}, []string{f.Sheets[0].Name, f.Sheets[1].Name, f.Sheets[2].Name})
+	var ids []int
+	for i := range f.Sheets {
+		models, err := ReadFile[*writeTableTmp](testFile, WithSheetIndex(i))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		for _, m := range models {
+			ids = append(ids, m.ID)
+		}
+	}
+	equal(t, []int{1, 2, 3, 4, 5}, ids)
+
+	// Every sheet gets its own table
+	parts := zipParts(t, mustReadFile(t, testFile))
+	equal(t, true, strings.Contains(parts["xl/tables/table1.xml"], `name="Fruits_List" displayName="Fruits_List" ref="A1:C3"`))
+	equal(t, true, strings.Contains(parts["xl/tables/table3.xml"], `name="Fruits_List_3" displayName="Fruits_List_3" ref="A1:C2"`))
+}
+
+func TestWriteStreamEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteStreamTo(buf, slices.Values([]*writeStreamTmp{})); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 1, f.Sheet["Audit"].MaxRow)
+
+	err = WriteStreamTo(&bytes.Buffer{}, slices.Values([]*writeMarshalTmp{{Status: 3}}))
+	equal(t, "error marshalling column \"Status\" in row 2: unknown status", err.Error())
+}
+
+func TestWriteStreamProgress(t *testing.T) {
+	writeProgress = nil
+	if err := WriteStreamTo(&bytes.Buffer{}, slices.Values([]*writeProgressTmp{{"a"}, {"b"}})); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, [][2]int{{1, -1}, {2, -1}}, writeProgress)
+}
+
+func TestWriteChanError(t *testing.T) {
+	rows := make(chan *writeMarshalTmp)
+	sent := make(chan int)
+	go func() {
+		count := 0
+		for range 3 {
+			rows <- &writeMarshalTmp{Status: 3}
+			count++
+		}
+		close(rows)
+		sent <- count
+	}()
+	err := WriteChanTo(&bytes.Buffer{}, rows)
+	equal(t, "error marshalling column \"Status\" in row 2: unknown status", err.Error())
+	// The remaining rows are drained, so the sender does not block
+	equal(t, 3, <-sent)
+
+	// The error is returned before the channel is closed
+	rows = make(chan *writeMarshalTmp)
+	go func() { rows <- &writeMarshalTmp{Status: 3} }()
+	if err = WriteChanTo(&bytes.Buffer{}, rows); err == nil {
+		t.Error("test failed: expected error")
+	}
+	rows <- &writeMarshalTmp{Status: 3}
+	close(rows)
+}
+
+func mustReadFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	return data
+}
@@ -0,0 +1,638 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+var ErrInvalidWorkbook = errors.New("exl: invalid workbook")
+
+// shiftRefRegexp matches the parts of a cell reference, e.g. "$A", "$" and "2" of "$A$2".
+var shiftRefRegexp = regexp.MustCompile(`^(\$?)([A-Z]+)(\$?)([0-9]+)$`)
+
+// builtInNumFmts are the number formats with an implicit format code,
+// as understood by the xlsx library.
+var builtInNumFmts = map[int]string{
+	0:  "general",
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	9:  "0%",
+	10: "0.00%",
+	11: "0.00e+00",
+	12: "# ?/?",
+	13: "# ??/??",
+	14: "mm-dd-yy",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm am/pm",
+	19: "h:mm:ss am/pm",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "m/d/yy h:mm",
+	37: "#,##0 ;(#,##0)",
+	38: "#,##0 ;[red](#,##0)",
+	39: "#,##0.00;(#,##0.00)",
+	40: "#,##0.00;[red](#,##0.00)",
+	41: `_(* #,##0_);_(* \(#,##0\);_(* "-"_);_(@_)`,
+	42: `_("$"* #,##0_);_("$* \(#,##0\);_("$"* "-"_);_(@_)`,
+	43: `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`,
+	44: `_("$"* #,##0.00_);_("$"* \(#,##0.00\);_("$"* "-"??_);_(@_)`,
+	45: "mm:ss",
+	46: "[h]:mm:ss",
+	47: "mmss.0",
+	48: "##0.0e+0",
+	49: "@",
+}
+
+type (
+	// streamWorkbook is an xlsx file read from the zip archive part by part, see ReadStream.
+	streamWorkbook struct {
+		zr     *zip.Reader
+		is1904 bool
+		names  []string
+		// The parts of the sheets, "" if missing
+		parts             []string
+		stylesPart        string
+		sharedStringsPart string
+	}
+	// streamSheet is a sheet decoded from the zip archive while its rows are read.
+	// Only the shared strings and the number formats of the styles are kept in memory.
+	streamSheet struct {
+		*streamWorkbook
+		part          string
+		sharedStrings []string
+		numFmts       []string
+		// The dimension, once computed, as it may take a pass over the sheet
+		maxRow, maxCol int
+		hasDimension   bool
+	}
+	// sharedFormula is the formula of the first cell of a shared formula,
+	// the other cells shift its references by their distance to the cell.
+	sharedFormula struct {
+		columnIndex, rowIndex int
+		formula               string
+	}
+	xmlWorkbook struct {
+		WorkbookPr struct {
+			Date1904 bool `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			Id   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xmlStyleSheet struct {
+		NumFmts []struct {
+			NumFmtId   int    `xml:"numFmtId,attr"`
+			FormatCode string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtId int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+)
+
+// ReadStream reads an xlsx file from the given io.ReaderAt,
+// e.g. a multipart.File, without loading the workbook:
+// the rows of the sheet are decoded from the zip archive one at a time,
+// other sheets are not read at all.
+// Each row is parsed and unmarshalled into a slice of `T`, like ReadParsed.
+func ReadStream[T any](reader io.ReaderAt, size int64, opts ...ReadOption) ([]T, error) {
+	return ReadStreamContext[T](context.Background(), reader, size, opts...)
+}
+
+// ReadStreamContext is ReadStream with a context, which is checked between rows,
+// see ReadParsedContext.
+func ReadStreamContext[T any](ctx context.Context, reader io.ReaderAt, size int64, opts ...ReadOption) ([]T, error) {
+	return collectRows(rowsStream[T](ctx, reader, size, opts...))
+}
+
+// ReadStreamFile reads an xlsx file at the given file path, see ReadStream.
+func ReadStreamFile[T any](file string, opts ...ReadOption) ([]T, error) {
+	return collectRows(RowsStreamFile[T](file, opts...))
+}
+
+// RowsStream returns an iterator over the rows of an xlsx file read from the given io.ReaderAt,
+// decoded while they are iterated, see ReadStream and Rows.
+func RowsStream[T any](reader io.ReaderAt, size int64, opts ...ReadOption) iter.Seq2[T, error] {
+	return rowsStream[T](context.Background(), reader, size, opts...)
+}
+
+func rowsStream[T any](ctx context.Context, reader io.ReaderAt, size int64, opts ...ReadOption) iter.Seq2[T, error] {
+	return readSheet[T](ctx, func(*ReadConfig) (workbookBackend, error) {
+		return openStreamWorkbook(reader, size)
+	}, opts...)
+}
+
+// RowsStreamFile returns an iterator over the rows of an xlsx file at the given file path,
+// see RowsStream. The file is opened when the iteration starts.
+func RowsStreamFile[T any](file string, opts ...ReadOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		f, err := os.Open(file)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer func() { _ = f.Close() }()
+		info, err := f.Stat()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		RowsStream[T](f, info.Size(), opts...)(yield)
+	}
+}
+
+// openStreamWorkbook reads the workbook and looks up the parts of the sheets,
+// the shared strings and the styles.
+func openStreamWorkbook(reader io.ReaderAt, size int64) (*streamWorkbook, error) {
+	zr, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	wb := &streamWorkbook{zr: zr}
+	workbookPart := "xl/workbook.xml"
+	var rels xmlRelationships
+	if wb.has("_rels/.rels") {
+		if err = wb.unmarshal("_rels/.rels", &rels); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range rels.Relationships {
+		if r.Type == relationshipTypePrefix+"officeDocument" {
+			workbookPart = targetPart("", r.Target)
+		}
+	}
+	var workbook xmlWorkbook
+	if err = wb.unmarshal(workbookPart, &workbook); err != nil {
+		return nil, err
+	}
+	wb.is1904 = workbook.WorkbookPr.Date1904
+
+	rels = xmlRelationships{}
+	if err = wb.unmarshal(relationshipsPart(workbookPart), &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, r := range rels.Relationships {
+		target := targetPart(workbookPart, r.Target)
+		targets[r.Id] = target
+		switch r.Type {
+		case relationshipTypePrefix + "styles":
+			wb.stylesPart = target
+		case relationshipTypePrefix + "sharedStrings":
+			wb.sharedStringsPart = target
+		}
+	}
+	for _, sheet := range workbook.Sheets {
+		wb.names = append(wb.names, sheet.Name)
+		wb.parts = append(wb.parts, targets[sheet.Id])
+	}
+	return wb, nil
+}
+
+func (wb *streamWorkbook) sheetNames() []string { return wb.names }
+
+// sheet reads the shared strings and the styles for the sheet at the index.
+func (wb *streamWorkbook) sheet(index int) (sheetBackend, error) {
+	if wb.parts[index] == "" {
+		return nil, fmt.Errorf("%w: no part for sheet %q", ErrInvalidWorkbook, wb.names[index])
+	}
+	s := &streamSheet{streamWorkbook: wb, part: wb.parts[index]}
+	if wb.stylesPart != "" {
+		if err := s.readStyles(wb.stylesPart); err != nil {
+			return nil, err
+		}
+	}
+	if wb.sharedStringsPart != "" {
+		if err := s.readSharedStrings(wb.sharedStringsPart); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// has reports whether the zip archive has the part.
+func (wb *streamWorkbook) has(part string) bool {
+	for _, zf := range wb.zr.File {
+		if zf.Name == part {
+			return true
+		}
+	}
+	return false
+}
+
+// open opens the part of the zip archive.
+func (wb *streamWorkbook) open(part string) (io.ReadCloser, error) {
+	for _, zf := range wb.zr.File {
+		if zf.Name == part {
+			return zf.Open()
+		}
+	}
+	return nil, fmt.Errorf("%w: missing part %s", ErrInvalidWorkbook, part)
+}
+
+// unmarshal unmarshals the part.
+func (wb *streamWorkbook) unmarshal(part string, v any) error {
+	r, err := wb.open(part)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// readStyles reads the number format of every cell style.
+func (s *streamSheet) readStyles(part string) error {
+	var styles xmlStyleSheet
+	if err := s.unmarshal(part, &styles); err != nil {
+		return err
+	}
+	formats := make(map[int]string, len(styles.NumFmts))
+	for _, numFmt := range styles.NumFmts {
+		formats[numFmt.NumFmtId] = numFmt.FormatCode
+	}
+	s.numFmts = make([]string, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		if format, ok := builtInNumFmts[xf.NumFmtId]; ok {
+			s.numFmts[i] = format
+		} else {
+			s.numFmts[i] = formats[xf.NumFmtId]
+		}
+	}
+	return nil
+}
+
+// readSharedStrings reads the shared strings, rich text as plain text.
+func (s *streamSheet) readSharedStrings(part string) error {
+	r, err := s.open(part)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	d := xml.NewDecoder(r)
+	var text strings.Builder
+	inText, phonetic := false, false
+	for {
+		token, err := d.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				text.Reset()
+			case "t":
+				inText = !phonetic
+			case "rPh":
+				phonetic = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				s.sharedStrings = append(s.sharedStrings, text.String())
+			case "t":
+				inText = false
+			case "rPh":
+				phonetic = false
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+func (s *streamSheet) date1904() bool { return s.is1904 }
+
+// dimension returns the dimension of the sheet, as the xlsx library does:
+// from the dimension element if it is a range,
+// otherwise from the cells, which takes a pass over the sheet.
+// It is computed once.
+func (s *streamSheet) dimension() (maxRow, maxCol int, err error) {
+	if !s.hasDimension {
+		if s.maxRow, s.maxCol, err = s.readDimension(); err != nil {
+			return 0, 0, err
+		}
+		s.hasDimension = true
+	}
+	return s.maxRow, s.maxCol, nil
+}
+
+func (s *streamSheet) readDimension() (maxRow, maxCol int, err error) {
+	r, err := s.open(s.part)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = r.Close() }()
+	d := xml.NewDecoder(r)
+	hasRows := false
+	var pos cellPosition
+	for {
+		token, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "dimension":
+			if ref := attr(start, "ref"); strings.Contains(ref, ":") {
+				x, y, err := xlsx.GetCoordsFromCellIDString(ref[strings.Index(ref, ":")+1:])
+				if err != nil {
+					return 0, 0, fmt.Errorf("%w: dimension %s", ErrInvalidWorkbook, ref)
+				}
+				return y + 1, x + 1, nil
+			}
+		case "row":
+			hasRows = true
+			if err = pos.row(start); err != nil {
+				return 0, 0, err
+			}
+		case "c":
+			if err = pos.cell(start); err != nil {
+				return 0, 0, err
+			}
+			maxRow, maxCol = max(maxRow, pos.rowIndex+1), max(maxCol, pos.columnIndex+1)
+		}
+	}
+	if !hasRows {
+		return 0, 0, nil
+	}
+	return max(maxRow, 1), max(maxCol, 1), nil
+}
+
+// rows decodes the rows of the sheet.
+func (s *streamSheet) rows() iter.Seq2[sheetRow, error] {
+	return func(yield func(sheetRow, error) bool) {
+		maxRow, maxCol, err := s.dimension()
+		if err != nil {
+			yield(sheetRow{}, err)
+			return
+		}
+		r, err := s.open(s.part)
+		if err != nil {
+			yield(sheetRow{}, err)
+			return
+		}
+		defer func() { _ = r.Close() }()
+		d := xml.NewDecoder(r)
+		next := 0
+		// fill yields the missing rows before the row index
+		fill := func(rowIndex int) bool {
+			for ; next < min(rowIndex, maxRow); next++ {
+				if !yield(sheetRow{index: next}, nil) {
+					return false
+				}
+			}
+			return true
+		}
+		var (
+			pos          cellPosition
+			row          sheetRow
+			cell         *Cell
+			cellType     string
+			value        strings.Builder
+			inValue      bool
+			inlineString bool
+			phonetic     bool
+			formula      strings.Builder
+			formulaAttrs xml.StartElement
+			inFormula    bool
+			shared       = make(map[string]sharedFormula)
+		)
+		for {
+			token, err := d.RawToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				yield(sheetRow{}, err)
+				return
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "row":
+					if err = pos.row(t); err != nil {
+						yield(sheetRow{}, err)
+						return
+					}
+					row = sheetRow{index: pos.rowIndex, cells: make([]*Cell, maxCol)}
+				case "c":
+					if err = pos.cell(t); err != nil {
+						yield(sheetRow{}, err)
+						return
+					}
+					cell = &Cell{NumFmt: "general", date1904: s.is1904}
+					if style, err := strconv.Atoi(attr(t, "s")); err == nil && style >= 0 && style < len(s.numFmts) {
+						cell.NumFmt = s.numFmts[style]
+					}
+					cellType = attr(t, "t")
+					value.Reset()
+					formula.Reset()
+					formulaAttrs = xml.StartElement{}
+				case "f":
+					inFormula, formulaAttrs = true, t
+				case "v":
+					inValue = true
+				case "is":
+					inlineString = true
+				case "t":
+					inValue = inlineString && !phonetic
+				case "rPh":
+					phonetic = true
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "v", "t":
+					inValue = false
+				case "f":
+					inFormula = false
+				case "is":
+					inlineString = false
+				case "rPh":
+					phonetic = false
+				case "c":
+					if err = s.setCell(cell, cellType, value.String()); err != nil {
+						yield(sheetRow{}, fmt.Errorf("%w in cell %s", err, xlsx.GetCellIDStringFromCoords(pos.columnIndex, pos.rowIndex)))
+						return
+					}
+					cell.formula = s.formula(formulaAttrs, formula.String(), pos, shared)
+					if pos.columnIndex < len(row.cells) {
+						row.cells[pos.columnIndex] = cell
+					}
+				case "row":
+					if row.index >= maxRow {
+						fill(maxRow)
+						return
+					}
+					if !fill(row.index) || !yield(row, nil) {
+						return
+					}
+					next = row.index + 1
+				}
+			case xml.CharData:
+				if inValue {
+					value.Write(t)
+				} else if inFormula {
+					formula.Write(t)
+				}
+			}
+		}
+		fill(maxRow)
+	}
+}
+
+// formula returns the formula of the cell from its formula element.
+// The cells of a shared formula other than the first have no text,
+// their formula is the one of the first cell with the references shifted like Excel does.
+func (s *streamSheet) formula(f xml.StartElement, text string, pos cellPosition, shared map[string]sharedFormula) string {
+	if attr(f, "t") != "shared" {
+		return text
+	}
+	si := attr(f, "si")
+	if attr(f, "ref") != "" {
+		shared[si] = sharedFormula{columnIndex: pos.columnIndex, rowIndex: pos.rowIndex, formula: text}
+		return text
+	}
+	first, ok := shared[si]
+	if !ok {
+		return text
+	}
+	return shiftFormula(first.formula, pos.columnIndex-first.columnIndex, pos.rowIndex-first.rowIndex)
+}
+
+// shiftFormula moves the relative cell references of the formula by the given columns and rows,
+// e.g. "A2*$B$1" by 1 row to "A3*$B$1".
+func shiftFormula(formula string, columns, rows int) string {
+	return replaceCellRefs(formula, func(ref string) string {
+		parts := strings.Split(ref, ":")
+		for i, part := range parts {
+			match := shiftRefRegexp.FindStringSubmatch(part)
+			columnIndex := xlsx.ColLettersToIndex(match[2])
+			if match[1] == "" {
+				columnIndex += columns
+			}
+			rowNumber, _ := strconv.Atoi(match[4])
+			if match[3] == "" {
+				rowNumber += rows
+			}
+			parts[i] = match[1] + xlsx.ColIndexToLetters(columnIndex) + match[3] + strconv.Itoa(rowNumber)
+		}
+		return strings.Join(parts, ":")
+	})
+}
+
+// setCell sets the value and type of the cell by the type attribute of the cell element.
+// Text keeps its spaces, whether it is a shared string, an inline string or the result of a formula.
+func (s *streamSheet) setCell(cell *Cell, cellType, value string) error {
+	if cellType != "inlineStr" && cellType != "str" {
+		value = strings.Trim(value, " \t\n\r")
+	}
+	switch cellType {
+	case "s":
+		if value == "" {
+			break
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(s.sharedStrings) {
+			return fmt.Errorf("%w: shared string %s", ErrInvalidWorkbook, value)
+		}
+		cell.Value = s.sharedStrings[i]
+	case "inlineStr", "str":
+		cell.Value = value
+	case "b":
+		cell.Value, cell.cellType = value, CellTypeBool
+	case "e":
+		cell.Value, cell.cellType = value, CellTypeError
+	case "d":
+		cell.Value, cell.cellType = value, CellTypeDate
+	case "", "n":
+		cell.Value, cell.cellType = value, CellTypeNumeric
+	default:
+		return fmt.Errorf("%w: cell type %q", ErrInvalidWorkbook, cellType)
+	}
+	return nil
+}
+
+// cellPosition tracks the position of the row and cell elements,
+// which may omit their reference to follow the previous one.
+type cellPosition struct {
+	rowIndex, columnIndex int
+	started               bool
+}
+
+func (p *cellPosition) row(start xml.StartElement) error {
+	if r := attr(start, "r"); r != "" {
+		n, err := strconv.Atoi(r)
+		if err != nil || n < 1 {
+			return fmt.Errorf("%w: row %s", ErrInvalidWorkbook, r)
+		}
+		p.rowIndex = n - 1
+	} else if p.started {
+		p.rowIndex++
+	}
+	p.started = true
+	p.columnIndex = -1
+	return nil
+}
+
+func (p *cellPosition) cell(start xml.StartElement) error {
+	if r := attr(start, "r"); r != "" {
+		x, _, err := xlsx.GetCoordsFromCellIDString(r)
+		if err != nil {
+			return fmt.Errorf("%w: cell %s", ErrInvalidWorkbook, r)
+		}
+		p.columnIndex = x
+	} else {
+		p.columnIndex++
+	}
+	return nil
+}
+
+// attr returns the value of the attribute without namespace prefix.
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type readStreamTmp struct {
+	Name    string    `excel:"Name"`
+	Count   int       `excel:"Count"`
+	Amount  float64   `excel:"Amount"`
+	Active  bool      `excel:"Active"`
+	Created time.Time `excel:"Created"`
+	Day     string    `excel:"Day"`
+	Note    *string   `excel:"Note"`
+}
+
+func (*readStreamTmp) ReadConfigure(rc *ReadConfig) {
+	rc.SheetName = "Data"
+	// The gap row has empty numbers
+	rc.UnmarshalErrorHandling = UnmarshalErrorIgnore
+}
+
+// readStreamFile writes a workbook with a sheet before the data sheet,
+// shared rich text, a gap row and a formula.
+// The dates are written as serial numbers of the 1900 date system,
+// they are 1462 days later when read in the 1904 date system.
+func readStreamFile(t *testing.T, date1904 bool) []byte {
+	t.Helper()
+	f := xlsx.NewFile()
+	other, _ := f.AddSheet("Other")
+	other.AddRow().AddCell().SetString("not read")
+	other.AddRow().AddCell().SetString("either")
+	sheet, _ := f.AddSheet("Data")
+	header := sheet.AddRow()
+	for _, h := range []string{"Name", "Count", "Amount", "Active", "Created", "Day", "Note"} {
+		header.AddCell().SetString(h)
+	}
+	created := time.Date(2022, 5, 6, 12, 0, 0, 0, time.UTC)
+	row := sheet.AddRow()
+	row.AddCell().SetRichText([]xlsx.RichTextRun{{Text: "app"}, {Text: "le"}})
+	row.AddCell().SetInt(3)
+	row.AddCell().SetFloatWithFormat(1.25, "0.00")
+	row.AddCell().SetBool(true)
+	row.AddCell().SetDateWithOptions(created, xlsx.DateTimeOptions{Location: time.UTC, ExcelTimeFormat: xlsx.DefaultDateTimeFormat})
+	row.AddCell().SetDateWithOptions(created, xlsx.DateTimeOptions{Location: time.UTC, ExcelTimeFormat: "yyyy-mm-dd"})
+	row.AddCell().SetString(" note ")
+	sheet.AddRow()
+	row = sheet.AddRow()
+	formula := row.AddCell()
+	formula.SetStringFormula(`"pe"&"ar"`)
+	formula.Value = "pear"
+	row.AddCell().SetInt(-1)
+	buf := &bytes.Buffer{}
+	if err := writePackage(f, buf, func(p *xlsxPackage) error {
+		if date1904 {
+			// The xlsx library does not write the 1904 date system
+			p.set("xl/workbook.xml", bytes.Replace(p.parts["xl/workbook.xml"], []byte(`date1904="false"`), []byte(`date1904="true"`), 1))
+		}
+		return nil
+	}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+func TestReadStream(t *testing.T) {
+	for _, date1904 := range []bool{false, true} {
+		data := readStreamFile(t, date1904)
+		expected, err := ReadBinary[*readStreamTmp](data)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		models, err := ReadStream[*readStreamTmp](bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, expected, models)
+		equal(t, 3, len(models))
+		equal(t, "apple", models[0].Name)
+		equal(t, 1.25, models[0].Amount)
+		created, day := time.Date(2022, 5, 6, 12, 0, 0, 0, time.UTC), "2022-05-06"
+		if date1904 {
+			created, day = time.Date(2026, 5, 7, 12, 0, 0, 0, time.UTC), "2026-05-07"
+		}
+		equal(t, created, models[0].Created)
+		equal(t, day, models[0].Day)
+		equal(t, " note ", *models[0].Note)
+		equal(t, readStreamTmp{Note: new(string)}, *models[1])
+		equal(t, "pear", models[2].Name)
+		equal(t, -1, models[2].Count)
+	}
+}
+
+func TestReadStreamWriteStream(t *testing.T) {
+	note := "a <note> & more"
+	data := []*writeStreamTmp{
+		{1, "apple", 1.5, true, time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC), &note},
+		{2, " pear ", 0.25, false, time.Date(2022, 5, 7, 0, 0, 0, 0, time.UTC), nil},
+	}
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := WriteStream(testFile, slices.Values(data)); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	expected, err := ReadFile[*writeStreamTmp](testFile, WithSheetName("Audit"))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	models, err := ReadStreamFile[*writeStreamTmp](testFile, WithSheetName("Audit"))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	// The xlsx library trims inline strings, the stream reader keeps their spaces
+	equal(t, "pear", expected[1].Name)
+	expected[1].Name = " pear "
+	equal(t, expected, models)
+	equal(t, *data[0], *models[0])
+}
+
+func TestRowsStream(t *testing.T) {
+	data := readStreamFile(t, false)
+	var names []string
+	for model, err := range RowsStream[*readStreamTmp](bytes.NewReader(data), int64(len(data))) {
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		names = append(names, model.Name)
+		break
+	}
+	equal(t, []string{"apple"}, names)
+
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := os.WriteFile(testFile, data, 0o644); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	count := 0
+	for _, err := range RowsStreamFile[*readStreamTmp](testFile, WithDataStartRow(3)) {
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		count++
+	}
+	equal(t, 1, count)
+}
+
+func TestReadStreamErrors(t *testing.T) {
+	data := readStreamFile(t, false)
+	read := func(opts ...ReadOption) error {
+		_, err := ReadStream[*readStreamTmp](bytes.NewReader(data), int64(len(data)), opts...)
+		return err
+	}
+	equal(t, ErrSheetIndexOutOfRange, read(WithSheetIndex(2)))
+	equal(t, ErrHeaderRowIndexOutOfRange, read(WithHeaderRow(4)))
+	equal(t, ErrDataStartRowIndexOutOfRange, read(WithDataStartRow(4)))
+	if err := read(WithSheetName("Other"), WithStrictHeaders()); !errors.Is(err, ErrMissingColumns) {
+		t.Error("test failed: expected missing columns, got", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadStreamContext[*readStreamTmp](ctx, bytes.NewReader(data), int64(len(data))); err != context.Canceled {
+		t.Error("test failed: expected canceled, got", err)
+	}
+	if _, err := ReadStream[*readStreamTmp](bytes.NewReader([]byte("no zip")), 6); err == nil {
+		t.Error("test failed: expected error reading an invalid file")
+	}
+	if _, err := ReadStreamFile[*readStreamTmp](path.Join(t.TempDir(), "does_not_exist.xlsx")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("test failed: expected missing file, got", err)
+	}
+}
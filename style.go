@@ -0,0 +1,71 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	// CellStyle is the style of written cells,
+	// set for a column with tag options or for the header row with WriteConfig.HeaderStyle.
+	CellStyle struct {
+		// Number format, e.g. "#,##0.00" or "yyyy-mm-dd".
+		Format string
+		// Horizontal alignment, "left", "center" or "right".
+		Align string
+		// Wrap text in the cell.
+		Wrap bool
+		// Bold font.
+		Bold bool
+		// Font color in ARGB, e.g. "FFFF0000".
+		FontColor string
+		// Solid fill color in ARGB, e.g. "FFD9D9D9".
+		FillColor string
+	}
+	// styleCache shares one xlsx.Style per distinct CellStyle,
+	// so cells with the same style don't each hold a copy.
+	styleCache map[CellStyle]*xlsx.Style
+)
+
+// apply sets the style on the cell.
+// Must be called after the value is set, as setting a value resets the number format.
+func (c styleCache) apply(cell *xlsx.Cell, cs CellStyle) {
+	if cs.Format != "" {
+		cell.SetFormat(cs.Format)
+	}
+	// The number format is kept by the cell, not by the xlsx.Style
+	cs.Format = ""
+	if cs == (CellStyle{}) {
+		return
+	}
+	style, ok := c[cs]
+	if !ok {
+		style = xlsx.NewStyle()
+		if cs.Align != "" || cs.Wrap {
+			style.Alignment.Horizontal = cs.Align
+			style.Alignment.WrapText = cs.Wrap
+			style.ApplyAlignment = true
+		}
+		if cs.Bold || cs.FontColor != "" {
+			style.Font.Bold = cs.Bold
+			style.Font.Color = cs.FontColor
+			style.ApplyFont = true
+		}
+		if cs.FillColor != "" {
+			style.Fill = *xlsx.NewFill("solid", cs.FillColor, cs.FillColor)
+			style.ApplyFill = true
+		}
+		c[cs] = style
+	}
+	cell.SetStyle(style)
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+func TestStyleCache(t *testing.T) {
+	styles := styleCache{}
+	cell1, cell2, cell3 := &xlsx.Cell{}, &xlsx.Cell{}, &xlsx.Cell{}
+	cell1.SetFloat(1.5)
+	styles.apply(cell1, CellStyle{Format: "#,##0.00", Align: "right", Bold: true})
+	styles.apply(cell2, CellStyle{Format: "0%", Align: "right", Bold: true})
+	styles.apply(cell3, CellStyle{Format: "0.0"})
+
+	equal(t, "#,##0.00", cell1.NumFmt)
+	equal(t, "0%", cell2.NumFmt)
+	equal(t, "0.0", cell3.NumFmt)
+	if cell1.GetStyle() != cell2.GetStyle() {
+		t.Error("test failed: expected the same style for the same CellStyle")
+	}
+	equal(t, 1, len(styles))
+	equal(t, "right", cell1.GetStyle().Alignment.Horizontal)
+	equal(t, true, cell1.GetStyle().Font.Bold)
+
+	styles.apply(cell3, CellStyle{FillColor: "FFD9D9D9", Wrap: true})
+	equal(t, 2, len(styles))
+	equal(t, "FFD9D9D9", cell3.GetStyle().Fill.FgColor)
+	equal(t, true, cell3.GetStyle().Alignment.WrapText)
+}
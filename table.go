@@ -0,0 +1,209 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+// tableSpec is an Excel Table (ListObject) to be added to a sheet when the file is written,
+// as the xlsx library cannot write tables.
+type tableSpec struct {
+	sheetIndex int
+	name       string
+	style      string
+	ref        string
+	columns    []string
+}
+
+// layoutSheet applies the sheet options of the WriteConfig to the rows written in one go,
+// the header row at headerRow followed by the data rows up to the last row of the sheet.
+// A returned tableSpec has to be passed to writeFile.
+func layoutSheet(f *xlsx.File, sheet *xlsx.Sheet, wc *WriteConfig, headerRow int) *tableSpec {
+	if sheet.MaxCol == 0 || headerRow >= sheet.MaxRow {
+		return nil
+	}
+	if wc.FreezeHeader {
+		sheet.SheetViews = []xlsx.SheetView{{Pane: &xlsx.Pane{
+			YSplit:      float64(headerRow + 1),
+			TopLeftCell: xlsx.GetCellIDStringFromCoords(0, headerRow+1),
+			ActivePane:  "bottomLeft",
+			State:       "frozen",
+		}}}
+	}
+	topLeft := xlsx.GetCellIDStringFromCoords(0, headerRow)
+	bottomRight := xlsx.GetCellIDStringFromCoords(sheet.MaxCol-1, max(sheet.MaxRow-1, headerRow+1))
+	if !wc.Table {
+		if wc.AutoFilter {
+			sheet.AutoFilter = &xlsx.AutoFilter{TopLeftCell: topLeft, BottomRightCell: bottomRight}
+		}
+		return nil
+	}
+	sheetIndex := 0
+	for i, s := range f.Sheets {
+		if s == sheet {
+			sheetIndex = i
+		}
+	}
+	table := &tableSpec{sheetIndex: sheetIndex, name: wc.TableName, style: wc.TableStyle, ref: topLeft + ":" + bottomRight}
+	headers := make([]string, sheet.MaxCol)
+	for columnIndex := range headers {
+		cell, _ := sheet.Cell(headerRow, columnIndex)
+		headers[columnIndex] = cell.Value
+	}
+	// The header cells have to equal the table columns
+	table.columns = tableColumns(headers)
+	for columnIndex, name := range table.columns {
+		if name != headers[columnIndex] {
+			cell, _ := sheet.Cell(headerRow, columnIndex)
+			cell.SetString(name)
+		}
+	}
+	return table
+}
+
+// tableColumns returns the names of the table columns for the headers,
+// which have to be unique and not empty.
+func tableColumns(headers []string) []string {
+	columns := make([]string, len(headers))
+	used := make(map[string]bool, len(headers))
+	for columnIndex, header := range headers {
+		name := header
+		if name == "" {
+			name = "Column" + strconv.Itoa(columnIndex+1)
+		}
+		for i := 2; used[strings.ToLower(name)]; i++ {
+			name = header + strconv.Itoa(i)
+		}
+		used[strings.ToLower(name)] = true
+		columns[columnIndex] = name
+	}
+	return columns
+}
+
+// writeFile writes the file with its tables to w.
+func writeFile(f *xlsx.File, tables []*tableSpec, w io.Writer) error {
+	return writePackage(f, w, addTables(tables))
+}
+
+// saveFile writes the file with its tables to the path.
+func saveFile(f *xlsx.File, tables []*tableSpec, path string) error {
+	return savePackage(f, path, addTables(tables))
+}
+
+// addTables returns the func adding the tables to the package, nil without tables.
+func addTables(tables []*tableSpec) func(p *xlsxPackage) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	return func(p *xlsxPackage) error {
+		usedNames := make(map[string]bool, len(tables))
+		for _, table := range tables {
+			if err := p.addTable(table, usedNames); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addTable adds the table part and references it from the sheet.
+// Table names have to be unique in the workbook, a used name gets a suffix "_2", "_3", ...
+func (p *xlsxPackage) addTable(table *tableSpec, usedNames map[string]bool) error {
+	id := 1
+	for p.parts[fmt.Sprintf("xl/tables/table%d.xml", id)] != nil {
+		id++
+	}
+	part := fmt.Sprintf("xl/tables/table%d.xml", id)
+	name := uniqueTableName(table.name, id, usedNames)
+
+	p.set(part, []byte(table.xml(id, name)))
+	p.addContentType(part, "application/vnd.openxmlformats-officedocument.spreadsheetml.table+xml")
+
+	sheet := sheetPart(table.sheetIndex)
+	rID, err := p.addRelationship(sheet, "table", part)
+	if err != nil {
+		return err
+	}
+	tablePart := fmt.Sprintf(`<tablePart r:id="%s"/>`, rID)
+	content := string(p.parts[sheet])
+	if start := strings.Index(content, `<tableParts count="`); start >= 0 {
+		// Further table on the same sheet
+		countStart := start + len(`<tableParts count="`)
+		countEnd := countStart + strings.Index(content[countStart:], `"`)
+		count, _ := strconv.Atoi(content[countStart:countEnd])
+		content = content[:countStart] + strconv.Itoa(count+1) + content[countEnd:]
+		p.set(sheet, []byte(strings.Replace(content, "</tableParts>", tablePart+"</tableParts>", 1)))
+		return nil
+	}
+	return p.insertElement(sheet, `<tableParts count="1">`+tablePart+`</tableParts>`, "<extLst", "</worksheet>")
+}
+
+// xml returns the table part.
+func (table *tableSpec) xml(id int, name string) string {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header)
+	fmt.Fprintf(b, `<table xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" id="%d" name="%s" displayName="%s" ref="%s" totalsRowShown="0">`, id, name, name, table.ref)
+	fmt.Fprintf(b, `<autoFilter ref="%s"/><tableColumns count="%d">`, table.ref, len(table.columns))
+	for i, column := range table.columns {
+		fmt.Fprintf(b, `<tableColumn id="%d" name="`, i+1)
+		_ = xml.EscapeText(b, []byte(column))
+		b.WriteString(`"/>`)
+	}
+	b.WriteString(`</tableColumns>`)
+	if table.style != "" {
+		b.WriteString(`<tableStyleInfo name="`)
+		_ = xml.EscapeText(b, []byte(table.style))
+		b.WriteString(`" showFirstColumn="0" showLastColumn="0" showRowStripes="1" showColumnStripes="0"/>`)
+	}
+	b.WriteString(`</table>`)
+	return b.String()
+}
+
+// uniqueTableName returns the tableName, with a suffix "_2", "_3", ... if it is used already.
+func uniqueTableName(name string, id int, usedNames map[string]bool) string {
+	name = tableName(name, id)
+	for i, base := 2, name; usedNames[strings.ToLower(name)]; i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	usedNames[strings.ToLower(name)] = true
+	return name
+}
+
+// tableName returns a valid table name, names may only contain letters, digits, "_", "\" and ".",
+// and must start with a letter, "_" or "\".
+// An empty name gets the default name "Table1", "Table2", ...
+func tableName(name string, id int) string {
+	if name == "" {
+		return "Table" + strconv.Itoa(id)
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && r != '\\' {
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			if r != '.' && !unicode.IsDigit(r) {
+				r = '_'
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+type writeTableTmp struct {
+	ID   int    `excel:"ID"`
+	Name string `excel:"Name"`
+	Alt  string `excel:"name"`
+}
+
+func (*writeTableTmp) WriteConfigure(wc *WriteConfig) {
+	wc.FreezeHeader = true
+	wc.Table = true
+	wc.TableName = "Fruits List"
+}
+
+func (*writeTableTmp) ReadConfigure(_ *ReadConfig) {}
+
+type writeFilterTmp struct {
+	ID int `excel:"ID"`
+}
+
+func (*writeFilterTmp) WriteConfigure(wc *WriteConfig) {
+	wc.AutoFilter = true
+}
+
+func zipParts(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	parts := make(map[string]string)
+	for _, zf := range zr.File {
+		rc, _ := zf.Open()
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		parts[zf.Name] = string(b)
+	}
+	return parts
+}
+
+func TestTableName(t *testing.T) {
+	equal(t, "Table3", tableName("", 3))
+	equal(t, "Fruits_List", tableName("Fruits List", 1))
+	equal(t, "_2022.Q1", tableName("2022.Q1", 1))
+	equal(t, "商品", tableName("商品", 1))
+}
+
+func TestWriteTable(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteTo(buf, []*writeTableTmp{{1, "apple", "a"}, {2, "pear", "p"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	parts := zipParts(t, buf.Bytes())
+	table := parts["xl/tables/table1.xml"]
+	for _, s := range []string{
+		`id="1" name="Fruits_List" displayName="Fruits_List" ref="A1:C3"`,
+		`<autoFilter ref="A1:C3"/>`,
+		`<tableColumn id="1" name="ID"/><tableColumn id="2" name="Name"/><tableColumn id="3" name="name2"/>`,
+		`<tableStyleInfo name="TableStyleMedium2"`,
+	} {
+		if !strings.Contains(table, s) {
+			t.Error("test failed: table misses " + s + ": " + table)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<tableParts count="1"><tablePart r:id="rId`) {
+		t.Error("test failed: missing tableParts: " + sheet)
+	}
+	if !strings.Contains(sheet, `state="frozen"`) || !strings.Contains(sheet, `topLeftCell="A2"`) {
+		t.Error("test failed: header is not frozen: " + sheet)
+	}
+	if strings.Contains(sheet, "<autoFilter") {
+		t.Error("test failed: sheet autoFilter overlaps the table")
+	}
+	if !strings.Contains(parts["xl/worksheets/_rels/sheet1.xml.rels"], `Target="../tables/table1.xml"`) {
+		t.Error("test failed: missing table relationship")
+	}
+	if !strings.Contains(parts["[Content_Types].xml"], `PartName="/xl/tables/table1.xml"`) {
+		t.Error("test failed: missing table content type")
+	}
+
+	// Header cells are renamed like the table columns
+	models, err := ReadBinary[*writeTableTmp](buf.Bytes(), WithReadConfig(func(rc *ReadConfig) {
+		rc.SkipUnknownColumns = true
+	}))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []*writeTableTmp{{1, "apple", ""}, {2, "pear", ""}}, models)
+}
+
+func TestWriteAutoFilter(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	if err := Write(testFile, []*writeFilterTmp{}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteTo(buf, []*writeFilterTmp{{1}, {2}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := zipParts(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<autoFilter ref="A1:A3"/>`) {
+		t.Error("test failed: missing autoFilter: " + sheet)
+	}
+}
+
+func TestWriterTables(t *testing.T) {
+	w := NewWriter()
+	w.Configure(func(wc *WriteConfig) {
+		wc.Table = true
+		wc.TableName = "Data"
+	})
+	if err := w.Write("ints", []int{1, 2}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if err := w.Write("ints", []int{3}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if err := w.Write("structs", []*writeFilterTmp{{1}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	parts := zipParts(t, buf.Bytes())
+	equal(t, true, strings.Contains(parts["xl/tables/table1.xml"], `name="Data" displayName="Data" ref="A1:A3"`))
+	equal(t, true, strings.Contains(parts["xl/tables/table2.xml"], `name="Data_2" displayName="Data_2" ref="A4:A5"`))
+	equal(t, true, strings.Contains(parts["xl/tables/table3.xml"], `name="Data_3" displayName="Data_3" ref="A1:A2"`))
+	equal(t, true, strings.Contains(parts["xl/worksheets/sheet1.xml"], `<tableParts count="2">`))
+	equal(t, true, strings.Contains(parts["xl/worksheets/sheet2.xml"], `<tableParts count="1">`))
+}
@@ -0,0 +1,556 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+var (
+	ErrInvalidTemplate = errors.New("exl: invalid template")
+
+	// templateRangeRegexp matches the cell starting a repeat region, e.g. "{{range .Items}}".
+	templateRangeRegexp = regexp.MustCompile(`^\{\{-?\s*range\s+(\.[\p{L}\p{N}_.]*)\s*-?\}\}$`)
+	// templateEndRegexp matches the cell ending a repeat region.
+	templateEndRegexp = regexp.MustCompile(`^\{\{-?\s*end\s*-?\}\}$`)
+	// templateFieldRegexp matches a cell holding a single field, e.g. "{{.Total}}",
+	// which is written with the type of the field instead of as text.
+	templateFieldRegexp = regexp.MustCompile(`^\{\{-?\s*(\.[\p{L}\p{N}_.]*)\s*-?\}\}$`)
+	// templateRefRegexp splits a cell reference into column and row, e.g. "$D$7".
+	templateRefRegexp = regexp.MustCompile(`^(\$?[A-Z]+\$?)([0-9]+)$`)
+)
+
+type (
+	// Template is a workbook designed in Excel with placeholders,
+	// which is rendered with Go data, see Template.Render.
+	Template struct {
+		file *xlsx.File
+		// The parts of the template, to keep images the xlsx library drops.
+		pkg       *xlsxPackage
+		templates map[string]*template.Template
+	}
+	// templateRow is a row of a repeat region, copied for every element.
+	templateRow struct {
+		height float64
+		cells  []templateCell
+	}
+	// templateRegion is a rendered repeat region, whose rows replace
+	// the rows from the {{range}} row to the {{end}} row.
+	templateRegion struct {
+		start, end int // Row indexes of the {{range}} and {{end}} rows
+		n          int // Number of rendered rows
+	}
+	templateCell struct {
+		columnIndex    int
+		value          string
+		formula        string
+		numFmt         string
+		style          *xlsx.Style
+		hMerge, vMerge int
+	}
+)
+
+// OpenTemplate opens the template file.
+func OpenTemplate(file string) (*Template, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return OpenTemplateBinary(data)
+}
+
+// OpenTemplateBinary opens the template from the bytes of an xlsx file.
+func OpenTemplateBinary(data []byte) (*Template, error) {
+	f, err := xlsx.OpenBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := readPackage(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{file: f, pkg: pkg, templates: make(map[string]*template.Template)}, nil
+}
+
+// File returns the workbook of the template, e.g. to make further changes after Render.
+func (t *Template) File() *xlsx.File { return t.file }
+
+// Render replaces the placeholders in all sheets with the data.
+// Cells hold text/template actions with the data as dot, e.g. "Customer: {{.Customer}}".
+// A cell with nothing but a field, e.g. "{{.Total}}", gets the value of the field like Write does,
+// so numbers and dates stay numbers and dates, keeping the number format of the cell.
+//
+// Rows between a row starting with "{{range .Items}}" and a row starting with "{{end}}"
+// are repeated for every element of the slice, with the element as dot.
+// The marker rows are removed. A range over a missing field or no slice returns ErrInvalidTemplate.
+// Styles, merged cells and images are kept.
+//
+// Formulas are adjusted like Excel does when inserting rows:
+// references below the region move down, ranges spanning the region grow to the rendered rows,
+// e.g. "SUM(D7:D7)" of a totals row becomes "SUM(D6:D8)" for three items,
+// and references within the repeated rows refer to the rows of the same item.
+// References to the rows of a region without items become #REF!.
+// References from other sheets are not adjusted.
+//
+// Render is meant to be called once.
+func (t *Template) Render(data any) error {
+	root := reflect.ValueOf(data)
+	for _, sheet := range t.file.Sheets {
+		if err := t.renderSheet(sheet, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the rendered workbook to the file.
+func (t *Template) Save(file string) error {
+	return savePackage(t.file, file, t.copyDrawings)
+}
+
+// Write writes the rendered workbook to w.
+func (t *Template) Write(w io.Writer) error {
+	return writePackage(t.file, w, t.copyDrawings)
+}
+
+func (t *Template) renderSheet(sheet *xlsx.Sheet, root reflect.Value) error {
+	for rowIndex := 0; rowIndex < sheet.MaxRow; rowIndex++ {
+		row, err := sheet.Row(rowIndex)
+		if err != nil {
+			return err
+		}
+		if path, ok := matchRow(row, templateRangeRegexp); ok {
+			n, err := t.renderRegion(sheet, rowIndex, path, root)
+			if err != nil {
+				return err
+			}
+			// Continue after the rendered rows
+			rowIndex += n - 1
+			continue
+		}
+		if _, ok := matchRow(row, templateEndRegexp); ok {
+			return fmt.Errorf("%w: {{end}} without {{range}} in row %d of sheet %s", ErrInvalidTemplate, rowIndex+1, sheet.Name)
+		}
+		if err = row.ForEachCell(func(cell *xlsx.Cell) error {
+			return t.renderCell(cell, root)
+		}, xlsx.SkipEmptyCells); err != nil {
+			return fmt.Errorf("error rendering row %d of sheet %s: %w", rowIndex+1, sheet.Name, err)
+		}
+	}
+	return nil
+}
+
+// renderRegion replaces the repeat region starting at the row with rendered copies of its rows
+// and returns the number of rows rendered.
+func (t *Template) renderRegion(sheet *xlsx.Sheet, startRowIndex int, path string, root reflect.Value) (int, error) {
+	endRowIndex := -1
+	for rowIndex := startRowIndex + 1; rowIndex < sheet.MaxRow && endRowIndex < 0; rowIndex++ {
+		row, err := sheet.Row(rowIndex)
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := matchRow(row, templateEndRegexp); ok {
+			endRowIndex = rowIndex
+		} else if _, ok = matchRow(row, templateRangeRegexp); ok {
+			return 0, fmt.Errorf("%w: nested {{range}} in row %d of sheet %s", ErrInvalidTemplate, rowIndex+1, sheet.Name)
+		}
+	}
+	if endRowIndex < 0 {
+		return 0, fmt.Errorf("%w: {{range}} without {{end}} in row %d of sheet %s", ErrInvalidTemplate, startRowIndex+1, sheet.Name)
+	}
+	items, ok := resolveField(root, path)
+	if !ok {
+		return 0, fmt.Errorf("%w: {{range %s}} in row %d of sheet %s does not resolve", ErrInvalidTemplate, path, startRowIndex+1, sheet.Name)
+	}
+	// A nil slice, pointer or interface has no items
+	for ok && (items.Kind() == reflect.Pointer || items.Kind() == reflect.Interface) {
+		items, ok = items.Elem(), !items.IsNil()
+	}
+	if ok && items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return 0, fmt.Errorf("%w: {{range %s}} in row %d of sheet %s is no slice", ErrInvalidTemplate, path, startRowIndex+1, sheet.Name)
+	}
+
+	rows := make([]templateRow, 0, endRowIndex-startRowIndex-1)
+	for rowIndex := startRowIndex + 1; rowIndex < endRowIndex; rowIndex++ {
+		row, _ := sheet.Row(rowIndex)
+		tr := templateRow{height: row.GetHeight()}
+		_ = row.ForEachCell(func(cell *xlsx.Cell) error {
+			columnIndex, _ := cell.GetCoordinates()
+			tr.cells = append(tr.cells, templateCell{
+				columnIndex: columnIndex,
+				value:       cell.Value,
+				formula:     cell.Formula(),
+				numFmt:      cell.NumFmt,
+				style:       cell.GetStyle(),
+				hMerge:      cell.HMerge,
+				vMerge:      cell.VMerge,
+			})
+			return nil
+		})
+		rows = append(rows, tr)
+	}
+	for rowIndex := endRowIndex; rowIndex >= startRowIndex; rowIndex-- {
+		if err := sheet.RemoveRowAtIndex(rowIndex); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+	if ok {
+		n = items.Len() * len(rows)
+	}
+	region := templateRegion{start: startRowIndex, end: endRowIndex, n: n}
+	for i := 0; i < n; i++ {
+		tr := rows[i%len(rows)]
+		row, err := sheet.AddRowAtIndex(startRowIndex + i)
+		if err != nil {
+			return 0, err
+		}
+		if tr.height > 0 {
+			row.SetHeight(tr.height)
+		}
+		item := items.Index(i / len(rows))
+		for _, tc := range tr.cells {
+			cell := row.GetCell(tc.columnIndex)
+			if tc.formula != "" {
+				cell.SetFormula(region.formula(tc.formula, i/len(rows)))
+			} else {
+				cell.SetString(tc.value)
+			}
+			cell.NumFmt = tc.numFmt
+			cell.SetStyle(tc.style)
+			cell.HMerge, cell.VMerge = tc.hMerge, tc.vMerge
+			if err = t.renderCell(cell, item); err != nil {
+				return 0, fmt.Errorf("error rendering row %d of sheet %s: %w", startRowIndex+i+1, sheet.Name, err)
+			}
+		}
+	}
+	return n, region.shiftFormulas(sheet)
+}
+
+// shiftFormulas adjusts the formulas of the sheet outside the rendered rows to the region.
+func (r templateRegion) shiftFormulas(sheet *xlsx.Sheet) error {
+	for rowIndex := 0; rowIndex < sheet.MaxRow; rowIndex++ {
+		if rowIndex == r.start && r.n > 0 {
+			// Skip the rendered rows
+			rowIndex += r.n - 1
+			continue
+		}
+		row, err := sheet.Row(rowIndex)
+		if err != nil {
+			return err
+		}
+		if err = row.ForEachCell(func(cell *xlsx.Cell) error {
+			formula := cell.Formula()
+			if formula == "" {
+				return nil
+			}
+			shifted := r.formula(formula, -1)
+			if shifted == formula {
+				return nil
+			}
+			if cell.Type() == xlsx.CellTypeStringFormula {
+				cell.SetStringFormula(shifted)
+			} else {
+				cell.SetFormula(shifted)
+			}
+			// The cached value is outdated
+			cell.Value = ""
+			return nil
+		}, xlsx.SkipEmptyCells); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formula returns the formula with its row references adjusted to the region,
+// for a formula of the rendered rows of the item, or -1 for a formula outside the region.
+// References to removed rows become #REF!, ranges shrink like in Excel.
+func (r templateRegion) formula(formula string, item int) string {
+	return replaceCellRefs(formula, func(ref string) string {
+		first, last, isRange := strings.Cut(ref, ":")
+		if !isRange {
+			first, _, ok := r.ref(first, item, false)
+			if !ok {
+				return "#REF!"
+			}
+			return first
+		}
+		first, firstRow, _ := r.ref(first, item, false)
+		last, lastRow, _ := r.ref(last, item, true)
+		if lastRow < firstRow {
+			return "#REF!"
+		}
+		return first + ":" + last
+	})
+}
+
+// ref returns the cell reference with its row adjusted to the region, and that row index, see row.
+func (r templateRegion) ref(ref string, item int, last bool) (string, int, bool) {
+	match := templateRefRegexp.FindStringSubmatch(ref)
+	if match == nil {
+		return ref, 0, true
+	}
+	rowNumber, _ := strconv.Atoi(match[2])
+	rowIndex, ok := r.row(rowNumber-1, item, last)
+	return match[1] + strconv.Itoa(rowIndex+1), rowIndex, ok
+}
+
+// row returns the rendered row index of a template row index.
+// Within the repeated rows, a formula of an item refers to the rows of that item,
+// a formula outside the region to the first rendered rows, or to the last ones for the end of a range.
+// It reports false for removed rows, i.e. the marker rows and the repeated rows of a region without items,
+// with the index of the next row, or of the previous row for the end of a range.
+func (r templateRegion) row(rowIndex, item int, last bool) (int, bool) {
+	switch {
+	case rowIndex < r.start:
+		return rowIndex, true
+	case rowIndex > r.end:
+		return rowIndex + r.n - (r.end - r.start + 1), true
+	case rowIndex == r.end:
+		if last {
+			return r.start + r.n - 1, false
+		}
+		return r.start + r.n, false
+	}
+	size := r.end - r.start - 1
+	offset := rowIndex - r.start - 1
+	switch {
+	case rowIndex == r.start || r.n == 0:
+		if last {
+			return r.start - 1, false
+		}
+		return r.start, false
+	case item >= 0:
+		return r.start + item*size + offset, true
+	case last:
+		return r.start + r.n - size + offset, true
+	}
+	return r.start + offset, true
+}
+
+// matchRow matches the first non-empty cell of the row.
+func matchRow(row *xlsx.Row, re *regexp.Regexp) (string, bool) {
+	var match []string
+	_ = row.ForEachCell(func(cell *xlsx.Cell) error {
+		if value := strings.TrimSpace(cell.Value); value != "" {
+			match = re.FindStringSubmatch(value)
+			return errStopIteration
+		}
+		return nil
+	}, xlsx.SkipEmptyCells)
+	if match == nil {
+		return "", false
+	}
+	return match[len(match)-1], true
+}
+
+var errStopIteration = errors.New("stop iteration")
+
+func (t *Template) renderCell(cell *xlsx.Cell, data reflect.Value) error {
+	if cell.Formula() != "" || !strings.Contains(cell.Value, "{{") {
+		return nil
+	}
+	text := strings.TrimSpace(cell.Value)
+	if match := templateFieldRegexp.FindStringSubmatch(text); match != nil {
+		if value, ok := resolveField(data, match[1]); ok {
+			numFmt := cell.NumFmt
+			if err := marshalXLSXCell(cell, value); err != nil {
+				return err
+			}
+			// Keep the number format designed in the template
+			if numFmt != "" && numFmt != "general" {
+				cell.NumFmt = numFmt
+			}
+			return nil
+		}
+	}
+	tmpl, ok := t.templates[cell.Value]
+	if !ok {
+		var err error
+		if tmpl, err = template.New("cell").Parse(cell.Value); err != nil {
+			return err
+		}
+		t.templates[cell.Value] = tmpl
+	}
+	b := &strings.Builder{}
+	var dot any
+	if data.IsValid() {
+		dot = data.Interface()
+	}
+	if err := tmpl.Execute(b, dot); err != nil {
+		return err
+	}
+	cell.SetString(b.String())
+	return nil
+}
+
+// resolveField returns the value of a field path like ".Customer.Name",
+// following struct fields and string map keys. "." is the data itself.
+func resolveField(data reflect.Value, path string) (reflect.Value, bool) {
+	value := data
+	for _, name := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if name == "" {
+			continue
+		}
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.Struct:
+			sf, ok := value.Type().FieldByName(name)
+			if !ok || !sf.IsExported() {
+				return reflect.Value{}, false
+			}
+			value = value.FieldByIndex(sf.Index)
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			value = value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !value.IsValid() {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return value, value.IsValid() && value.CanInterface()
+}
+
+type xmlContentTypes struct {
+	Defaults []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+// copyDrawings adds the drawings of the template sheets, like logos, which the xlsx library drops,
+// to the rendered package.
+func (t *Template) copyDrawings(p *xlsxPackage) error {
+	sheets, err := t.pkg.sheetParts()
+	if err != nil {
+		return err
+	}
+	var types xmlContentTypes
+	if err = xml.Unmarshal(t.pkg.parts["[Content_Types].xml"], &types); err != nil {
+		return err
+	}
+	copied := make(map[string]bool)
+	var copyPart func(name string) error
+	copyPart = func(name string) error {
+		if copied[name] || t.pkg.parts[name] == nil {
+			return nil
+		}
+		copied[name] = true
+		p.set(name, t.pkg.parts[name])
+		contentType := ""
+		for _, o := range types.Overrides {
+			if strings.TrimPrefix(o.PartName, "/") == name {
+				contentType = o.ContentType
+			}
+		}
+		if contentType != "" {
+			p.addContentType(name, contentType)
+		} else if i := strings.LastIndex(name, "."); i >= 0 {
+			for _, d := range types.Defaults {
+				if strings.EqualFold(d.Extension, name[i+1:]) {
+					p.addContentType("."+d.Extension, d.ContentType)
+				}
+			}
+		}
+		if rels := t.pkg.parts[relationshipsPart(name)]; rels != nil {
+			p.set(relationshipsPart(name), rels)
+			relationships, err := t.pkg.relationships(name)
+			if err != nil {
+				return err
+			}
+			for _, rel := range relationships {
+				if rel.TargetMode != "External" {
+					if err = copyPart(targetPart(name, rel.Target)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	for sheetIndex, sheet := range sheets {
+		relationships, err := t.pkg.relationships(sheet)
+		if err != nil {
+			return err
+		}
+		for _, rel := range relationships {
+			if rel.Type != relationshipTypePrefix+"drawing" {
+				continue
+			}
+			drawing := targetPart(sheet, rel.Target)
+			if err = copyPart(drawing); err != nil {
+				return err
+			}
+			id, err := p.addRelationship(sheetPart(sheetIndex), "drawing", drawing)
+			if err != nil {
+				return err
+			}
+			if err = p.insertElement(sheetPart(sheetIndex), fmt.Sprintf(`<drawing r:id="%s"/>`, id),
+				"<legacyDrawing", "<drawingHF", "<picture", "<oleObjects", "<controls", "<webPublishItems", "<tableParts", "<extLst", "</worksheet>"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sheetParts returns the part names of the worksheets in the order of the workbook.
+func (p *xlsxPackage) sheetParts() ([]string, error) {
+	const workbook = "xl/workbook.xml"
+	var wb struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(p.parts[workbook], &wb); err != nil {
+		return nil, err
+	}
+	relationships, err := p.relationships(workbook)
+	if err != nil {
+		return nil, err
+	}
+	sheets := make([]string, 0, len(wb.Sheets))
+	for _, sheet := range wb.Sheets {
+		for _, rel := range relationships {
+			if rel.Id == sheet.ID {
+				sheets = append(sheets, targetPart(workbook, rel.Target))
+			}
+		}
+	}
+	return sheets, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+type (
+	templateTmpReport struct {
+		Customer string
+		Date     time.Time
+		Items    []*templateTmpItem
+		Total    float64
+	}
+	templateTmpItem struct {
+		Name   string
+		Amount float64
+	}
+)
+
+func newTemplateTmp(t *testing.T) []byte {
+	t.Helper()
+	f := xlsx.NewFile()
+	sheet, _ := f.AddSheet("Report")
+	set := func(row, col int, value string) *xlsx.Cell {
+		cell, _ := sheet.Cell(row, col)
+		cell.SetString(value)
+		return cell
+	}
+	set(0, 0, "Customer: {{.Customer}}")
+	set(0, 1, "{{.Date}}").NumFmt = "yyyy-mm-dd"
+	set(1, 0, "{{range .Items}}")
+	name := set(2, 0, "{{.Name}}")
+	style := xlsx.NewStyle()
+	style.Font.Bold = true
+	style.ApplyFont = true
+	name.SetStyle(style)
+	set(2, 1, "{{.Amount}}").NumFmt = "#,##0.00"
+	set(3, 0, "{{end}}")
+	total := set(4, 0, "Total")
+	total.HMerge = 1
+	set(4, 2, "{{.Total}}")
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	return buf.Bytes()
+}
+
+func TestTemplateRender(t *testing.T) {
+	tmpl, err := OpenTemplateBinary(newTemplateTmp(t))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	report := &templateTmpReport{
+		Customer: "Coco",
+		Date:     time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC),
+		Items:    []*templateTmpItem{{"apple", 1.5}, {"pear", 2}, {"plum", 3.25}},
+		Total:    6.75,
+	}
+	if err = tmpl.Render(report); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	file := path.Join(t.TempDir(), "tmp.xlsx")
+	if err = tmpl.Save(file); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenFile(file)
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheets[0]
+	equal(t, 5, sheet.MaxRow)
+	cell := func(row, col int) *xlsx.Cell {
+		c, _ := sheet.Cell(row, col)
+		return c
+	}
+	equal(t, "Customer: Coco", cell(0, 0).Value)
+	equal(t, "yyyy-mm-dd", cell(0, 1).NumFmt)
+	date, _ := cell(0, 1).GetTime(false)
+	equal(t, report.Date, date)
+	for i, item := range report.Items {
+		equal(t, item.Name, cell(1+i, 0).Value)
+		equal(t, true, cell(1+i, 0).GetStyle().Font.Bold)
+		amount, _ := cell(1+i, 1).Float()
+		equal(t, item.Amount, amount)
+		equal(t, "#,##0.00", cell(1+i, 1).NumFmt)
+	}
+	equal(t, "Total", cell(4, 0).Value)
+	equal(t, 1, cell(4, 0).HMerge)
+	total, _ := cell(4, 2).Float()
+	equal(t, 6.75, total)
+}
+
+func TestTemplateRenderEmptyRegion(t *testing.T) {
+	tmpl, _ := OpenTemplateBinary(newTemplateTmp(t))
+	if err := tmpl.Render(map[string]any{"Customer": "Coco", "Items": []any(nil)}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := tmpl.File().Sheets[0]
+	equal(t, 2, sheet.MaxRow)
+	c, _ := sheet.Cell(0, 0)
+	equal(t, "Customer: Coco", c.Value)
+	c, _ = sheet.Cell(1, 0)
+	equal(t, "Total", c.Value)
+
+	// A missing field or no slice is no empty region
+	for _, data := range []any{
+		map[string]any{"Customer": "Coco"},
+		map[string]any{"Customer": "Coco", "Items": "none"},
+	} {
+		tmpl, _ = OpenTemplateBinary(newTemplateTmp(t))
+		if err := tmpl.Render(data); !errors.Is(err, ErrInvalidTemplate) {
+			t.Error("test failed: expected ErrInvalidTemplate, got:", err)
+		}
+	}
+}
+
+func TestTemplateRenderFormulas(t *testing.T) {
+	newTemplate := func() *Template {
+		f := xlsx.NewFile()
+		sheet, _ := f.AddSheet("Report")
+		for row, values := range [][]string{
+			{"Name", "Amount", "Tax", "Gross"},
+			{"{{range .Items}}"},
+			{"{{.Name}}", "{{.Amount}}", "=B3*0.1", "=B3+C3"},
+			{"{{end}}"},
+			{"Total", "=SUM(B3:B3)", "=SUM(C2:C4)", `=SUM(D1:D3)&"B3"&Other!B3`},
+			{"Share", "=B3/B5"},
+		} {
+			for col, value := range values {
+				c, _ := sheet.Cell(row, col)
+				if formula, ok := strings.CutPrefix(value, "="); ok {
+					c.SetFormula(formula)
+				} else {
+					c.SetString(value)
+				}
+			}
+		}
+		buf := &bytes.Buffer{}
+		if err := f.Write(buf); err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		tmpl, err := OpenTemplateBinary(buf.Bytes())
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		return tmpl
+	}
+	formulas := func(tmpl *Template) []string {
+		var formulas []string
+		sheet := tmpl.File().Sheets[0]
+		for row := 0; row < sheet.MaxRow; row++ {
+			for col := 1; col < 4; col++ {
+				if c, _ := sheet.Cell(row, col); c.Formula() != "" {
+					formulas = append(formulas, c.Formula())
+				}
+			}
+		}
+		return formulas
+	}
+
+	tmpl := newTemplate()
+	items := []*templateTmpItem{{"apple", 1.5}, {"pear", 2}, {"plum", 3.25}, {"kiwi", 1}}
+	if err := tmpl.Render(templateTmpReport{Items: items}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []string{
+		"B2*0.1", "B2+C2",
+		"B3*0.1", "B3+C3",
+		"B4*0.1", "B4+C4",
+		"B5*0.1", "B5+C5",
+		"SUM(B2:B5)", "SUM(C2:C5)", `SUM(D1:D5)&"B3"&Other!B3`,
+		"B2/B6",
+	}, formulas(tmpl))
+
+	// The rows of a region without items are removed
+	tmpl = newTemplate()
+	if err := tmpl.Render(templateTmpReport{}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, []string{"SUM(#REF!)", "SUM(#REF!)", `SUM(D1:D1)&"B3"&Other!B3`, "#REF!/B2"}, formulas(tmpl))
+}
+
+func TestTemplateRenderInvalid(t *testing.T) {
+	for _, values := range [][]string{{"{{range .Items}}"}, {"{{end}}"}, {"{{range .Customer}}", "{{end}}"}} {
+		f := xlsx.NewFile()
+		sheet, _ := f.AddSheet("Report")
+		for i, value := range values {
+			c, _ := sheet.Cell(i, 0)
+			c.SetString(value)
+		}
+		buf := &bytes.Buffer{}
+		_ = f.Write(buf)
+		tmpl, _ := OpenTemplateBinary(buf.Bytes())
+		if err := tmpl.Render(templateTmpReport{Customer: "Coco"}); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("test failed: expected ErrInvalidTemplate for %v, got %v", values, err)
+		}
+	}
+}
+
+func TestTemplateKeepDrawings(t *testing.T) {
+	pkg, err := readPackage(newTemplateTmp(t))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	// Add a logo, like Excel does
+	pkg.set("xl/media/image1.png", []byte("png"))
+	pkg.addContentType(".png", "image/png")
+	pkg.set("xl/drawings/drawing1.xml", []byte(`<xdr:wsDr></xdr:wsDr>`))
+	pkg.addContentType("xl/drawings/drawing1.xml", "application/vnd.openxmlformats-officedocument.drawing+xml")
+	if _, err = pkg.addRelationship("xl/drawings/drawing1.xml", "image", "xl/media/image1.png"); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if _, err = pkg.addRelationship(sheetPart(0), "drawing", "xl/drawings/drawing1.xml"); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf := &bytes.Buffer{}
+	_ = pkg.Write(buf)
+
+	tmpl, err := OpenTemplateBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if err = tmpl.Render(templateTmpReport{Customer: "Coco"}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf.Reset()
+	if err = tmpl.Write(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	parts := zipParts(t, buf.Bytes())
+	equal(t, "png", parts["xl/media/image1.png"])
+	equal(t, `<xdr:wsDr></xdr:wsDr>`, parts["xl/drawings/drawing1.xml"])
+	equal(t, true, strings.Contains(parts["xl/drawings/_rels/drawing1.xml.rels"], `Target="../media/image1.png"`))
+	equal(t, true, strings.Contains(parts["[Content_Types].xml"], `Extension="png"`))
+	equal(t, true, strings.Contains(parts["[Content_Types].xml"], `PartName="/xl/drawings/drawing1.xml"`))
+	equal(t, true, strings.Contains(parts["xl/worksheets/_rels/sheet1.xml.rels"], `Target="../drawings/drawing1.xml"`))
+	equal(t, true, strings.Contains(parts["xl/worksheets/sheet1.xml"], `<drawing r:id="`))
+	if _, err = xlsx.OpenBinary(buf.Bytes()); err != nil {
+		t.Error("test failed: " + err.Error())
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ValidationError is the error of a FieldError,
+	// if a value violates a rule of the validation tag, see ReadConfig.ValidateTagName.
+	ValidationError struct {
+		Rule  string // e.g. "min"
+		Param string // e.g. "0"
+	}
+	// validateFunc checks an unmarshalled value against one rule.
+	// empty reports whether the source cell was empty, see emptyCell.
+	validateFunc func(v reflect.Value, empty bool) error
+	// DuplicateError is the error of a FieldError,
+	// if a row repeats the value of a field with the tag option "unique".
+	DuplicateError struct {
+		FirstRowIndex int      // 0-based row index of the first row with the value.
+		Headers       []string // Headers of the columns forming the key.
+	}
+	// uniqueKey is a set of columns whose values must be unique across rows.
+	uniqueKey struct {
+		name    string
+		columns []int
+	}
+	// uniqueIndex remembers the first row of every key value seen.
+	uniqueIndex struct {
+		keys  []uniqueKey
+		first []map[string]int
+	}
+)
+
+var (
+	// Ensure ValidationError implements the error interface
+	_ error = ValidationError{}
+	// Ensure DuplicateError implements the error interface
+	_ error = DuplicateError{}
+)
+
+// Error implements error.
+func (e ValidationError) Error() string {
+	switch e.Rule {
+	case "required":
+		return "value is required"
+	case "min":
+		return fmt.Sprintf("value must be at least %s", e.Param)
+	case "max":
+		return fmt.Sprintf("value must be at most %s", e.Param)
+	case "len":
+		return fmt.Sprintf("length must be %s", e.Param)
+	case "oneof", "enum":
+		return fmt.Sprintf("value must be one of %s", strings.ReplaceAll(e.Param, "|", ", "))
+	case "regex":
+		return fmt.Sprintf("value must match %s", e.Param)
+	}
+	return fmt.Sprintf("value violates rule %s=%s", e.Rule, e.Param)
+}
+
+// validateRules lists the rules of the validation tag, mapped to whether they take a parameter.
+// Like for the excel tag, a comma-separated segment which does not start with a rule
+// belongs to the previous segment, so e.g. a regex may contain commas.
+var validateRules = map[string]bool{
+	"required": false,
+	"min":      true,
+	"max":      true,
+	"len":      true,
+	"oneof":    true,
+	"regex":    true,
+}
+
+// parseValidateTag compiles the rules of a validation tag,
+// e.g. `validate:"required,min=0,max=100,oneof=A|B"`, for a field of the given type.
+// required rejects empty cells, so a cell holding 0 or FALSE passes.
+// min and max compare numbers by value, and strings, slices and maps by length.
+// Rules other than required are skipped for empty cells and nil pointers,
+// so optional columns may be left blank.
+func parseValidateTag(tag string, typ reflect.Type) ([]validateFunc, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	rules := splitValidateTag(tag)
+	elemType := typ
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	validators := make([]validateFunc, 0, len(rules))
+	for _, r := range rules {
+		hasParam, ok := validateRules[r.Rule]
+		if !ok || hasParam == (r.Param == "") {
+			return nil, fmt.Errorf("%w: validation rule %q", ErrInvalidTag, segmentOf(r.Rule, r.Param))
+		}
+		fail := r
+		var check func(v reflect.Value) bool
+		switch r.Rule {
+		case "required":
+			validators = append(validators, func(_ reflect.Value, empty bool) error {
+				if empty {
+					return fail
+				}
+				return nil
+			})
+			continue
+		case "min", "max", "len":
+			limit, err := strconv.ParseFloat(r.Param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: validation rule %q: %w", ErrInvalidTag, segmentOf(r.Rule, r.Param), err)
+			}
+			measure := measureFunc(elemType, r.Rule == "len")
+			if measure == nil {
+				return nil, fmt.Errorf("%w: validation rule %q on %s", ErrInvalidTag, segmentOf(r.Rule, r.Param), typ)
+			}
+			switch r.Rule {
+			case "min":
+				check = func(v reflect.Value) bool { return measure(v) >= limit }
+			case "max":
+				check = func(v reflect.Value) bool { return measure(v) <= limit }
+			default:
+				check = func(v reflect.Value) bool { return measure(v) == limit }
+			}
+		case "oneof":
+			allowed := strings.Split(r.Param, "|")
+			check = func(v reflect.Value) bool {
+				s := fmt.Sprint(v.Interface())
+				for _, a := range allowed {
+					if s == a {
+						return true
+					}
+				}
+				return false
+			}
+		case "regex":
+			re, err := regexp.Compile(r.Param)
+			if err != nil {
+				return nil, fmt.Errorf("%w: validation rule %q: %w", ErrInvalidTag, segmentOf(r.Rule, r.Param), err)
+			}
+			check = func(v reflect.Value) bool { return re.MatchString(fmt.Sprint(v.Interface())) }
+		}
+		validators = append(validators, func(v reflect.Value, empty bool) error {
+			if empty {
+				return nil
+			}
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return nil
+				}
+				v = v.Elem()
+			}
+			if !v.CanInterface() || !check(v) {
+				return fail
+			}
+			return nil
+		})
+	}
+	return validators, nil
+}
+
+// splitValidateTag splits a validation tag into its rules, unchecked.
+func splitValidateTag(tag string) []ValidationError {
+	if tag == "" {
+		return nil
+	}
+	var rules []ValidationError
+	for _, segment := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(segment, "=")
+		if _, ok := validateRules[strings.TrimSpace(name)]; ok || len(rules) == 0 {
+			rules = append(rules, ValidationError{Rule: strings.TrimSpace(name), Param: param})
+		} else {
+			rules[len(rules)-1].Param += "," + segment
+		}
+	}
+	return rules
+}
+
+// measureFunc returns how min, max and len measure a value of the type:
+// numbers by value, everything with a length by length.
+func measureFunc(typ reflect.Type, length bool) func(v reflect.Value) float64 {
+	switch typ.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) float64 { return float64(len([]rune(v.String()))) }
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return func(v reflect.Value) float64 { return float64(v.Len()) }
+	}
+	if length {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) float64 { return float64(v.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v reflect.Value) float64 { return float64(v.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) float64 { return v.Float() }
+	}
+	return nil
+}
+
+func segmentOf(name, param string) string {
+	if param == "" {
+		return name
+	}
+	return name + "=" + param
+}
+
+// validate runs the validation rules of the field against the value unmarshalled from the cell.
+func (fi FieldInfo) validate(destField reflect.Value, cell *Cell, trimSpace bool) error {
+	empty := emptyCell(cell, trimSpace)
+	for _, v := range fi.validators {
+		if err := v(destField, empty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emptyCell reports whether the cell has no value, or only spaces with ReadConfig.TrimSpace.
+func emptyCell(cell *Cell, trimSpace bool) bool {
+	if trimSpace {
+		return strings.TrimSpace(cell.Value) == ""
+	}
+	return cell.Value == ""
+}
+
+// Error implements error.
+func (e DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate value of %s, first used in row %d", strings.Join(e.Headers, ", "), e.FirstRowIndex+1)
+}
+
+func addUniqueColumn(keys []uniqueKey, name string, columnIndex int) []uniqueKey {
+	for i := range keys {
+		if keys[i].name == name {
+			keys[i].columns = append(keys[i].columns, columnIndex)
+			return keys
+		}
+	}
+	return append(keys, uniqueKey{name: name, columns: []int{columnIndex}})
+}
+
+func newUniqueIndex(keys []uniqueKey) *uniqueIndex {
+	first := make([]map[string]int, len(keys))
+	for i := range first {
+		first[i] = make(map[string]int)
+	}
+	return &uniqueIndex{keys: keys, first: first}
+}
+
+// check records the key values of the row and returns a FieldError,
+// with a DuplicateError, for every key value which has been seen before.
+// Keys whose cells are all empty are not checked, see emptyCell, zero values are.
+func (u *uniqueIndex) check(row sheetRow, val reflect.Value, columnFields []FieldInfo, trimSpace bool) []FieldError {
+	var errs []FieldError
+	for i, key := range u.keys {
+		parts := make([]string, len(key.columns))
+		allEmpty := true
+		for j, columnIndex := range key.columns {
+			if !emptyCell(row.cell(columnIndex), trimSpace) {
+				allEmpty = false
+			}
+			v := fieldByIndex(val, columnFields[columnIndex].reflectFieldIndex)
+			for v.Kind() == reflect.Pointer && !v.IsNil() {
+				v = v.Elem()
+			}
+			if v.CanInterface() {
+				parts[j] = fmt.Sprint(v.Interface())
+			}
+		}
+		if allEmpty {
+			continue
+		}
+		value := strings.Join(parts, "\x00")
+		firstRowIndex, ok := u.first[i][value]
+		if !ok {
+			u.first[i][value] = row.index
+			continue
+		}
+		headers := make([]string, len(key.columns))
+		for j, columnIndex := range key.columns {
+			headers[j] = columnFields[columnIndex].Header
+		}
+		errs = append(errs, FieldError{
+			RowIndex:     row.index,
+			ColumnIndex:  key.columns[0],
+			ColumnHeader: headers[0],
+			Err:          DuplicateError{FirstRowIndex: firstRowIndex, Headers: headers},
+		})
+	}
+	return errs
+}
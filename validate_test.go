@@ -0,0 +1,209 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+)
+
+type readValidateTmp struct {
+	Code   string   `excel:"Code" validate:"required,len=3,regex=^[A-Z]{1,3}$"`
+	Score  int      `excel:"Score" validate:"min=0,max=100"`
+	Grade  string   `excel:"Grade" validate:"oneof=A|B|C"`
+	Weight *float64 `excel:"Weight" validate:"max=1.5"`
+}
+
+type readValidateOptionalTmp struct {
+	Grade string `excel:"Grade" validate:"oneof=A|B|C"`
+	Code  string `excel:"Code" validate:"regex=^[A-Z]+$"`
+}
+
+type readValidateRequiredTmp struct {
+	Price float64 `excel:"Price" validate:"required,min=0,max=100"`
+	Name  string  `excel:"Name" validate:"required"`
+}
+
+func TestReadValidation(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"Code", "Score", "Grade", "Weight"},
+		{"ABC", "90", "A", "1"},
+		{"", "101", "D", "0.5"},
+		{"AB", "-1", "B", "2"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	_, err := ReadFile[*readValidateTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect), WithMaxUnmarshalErrors(0))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 2, ColumnIndex: 0, ColumnHeader: "Code", Err: ValidationError{Rule: "required"}},
+			{RowIndex: 2, ColumnIndex: 1, ColumnHeader: "Score", Err: ValidationError{Rule: "max", Param: "100"}},
+			{RowIndex: 2, ColumnIndex: 2, ColumnHeader: "Grade", Err: ValidationError{Rule: "oneof", Param: "A|B|C"}},
+			{RowIndex: 3, ColumnIndex: 0, ColumnHeader: "Code", Err: ValidationError{Rule: "len", Param: "3"}},
+			{RowIndex: 3, ColumnIndex: 1, ColumnHeader: "Score", Err: ValidationError{Rule: "min", Param: "0"}},
+			{RowIndex: 3, ColumnIndex: 3, ColumnHeader: "Weight", Err: ValidationError{Rule: "max", Param: "1.5"}},
+		},
+	}, err)
+
+	_, err = ReadFile[*readValidateTmp](testFile)
+	equal(t, "error unmarshalling column \"Code\" in row 3: value is required", err.Error())
+
+	models, err := ReadFile[*readValidateTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 3, len(models))
+
+	// required rejects empty cells, not zero values
+	data = [][]string{
+		{"Price", "Name"},
+		{"0", "Apple"},
+		{"5", " "},
+	}
+	if err = WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	_, err = ReadFile[*readValidateRequiredTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect), WithTrimSpace(true))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 2, ColumnIndex: 1, ColumnHeader: "Name", Err: ValidationError{Rule: "required"}},
+		},
+	}, err)
+
+	// Other rules accept empty cells
+	data = [][]string{
+		{"Grade", "Code"},
+		{"", ""},
+		{"A", "AB"},
+		{"D", "ab"},
+	}
+	if err = WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	_, err = ReadFile[*readValidateOptionalTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 3, ColumnIndex: 0, ColumnHeader: "Grade", Err: ValidationError{Rule: "oneof", Param: "A|B|C"}},
+			{RowIndex: 3, ColumnIndex: 1, ColumnHeader: "Code", Err: ValidationError{Rule: "regex", Param: "^[A-Z]+$"}},
+		},
+	}, err)
+}
+
+func TestParseValidateTag(t *testing.T) {
+	str := reflect.TypeOf("")
+	t.Run("regex with commas", func(t *testing.T) {
+		validators, err := parseValidateTag("regex=^a{1,2}$,required", str)
+		if err != nil {
+			t.Fatal("test failed: " + err.Error())
+		}
+		equal(t, 2, len(validators))
+		equal(t, nil, validators[0](reflect.ValueOf("aa"), false))
+		equal(t, ValidationError{Rule: "regex", Param: "^a{1,2}$"}, validators[0](reflect.ValueOf("aaa"), false))
+		equal(t, nil, validators[0](reflect.ValueOf(""), true))
+		equal(t, ValidationError{Rule: "required"}, validators[1](reflect.ValueOf(""), true))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, tag := range []string{"unknown", "min", "required=1", "min=x", "regex=(", "min=1"} {
+			typ := str
+			if tag == "min=1" {
+				typ = reflect.TypeOf(struct{}{})
+			}
+			if _, err := parseValidateTag(tag, typ); !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("test failed: expected ErrInvalidTag for %q, got: %v", tag, err)
+			}
+		}
+	})
+	t.Run("messages", func(t *testing.T) {
+		equal(t, "value must be one of A, B", ValidationError{Rule: "oneof", Param: "A|B"}.Error())
+		equal(t, "value must match ^a$", ValidationError{Rule: "regex", Param: "^a$"}.Error())
+		equal(t, "value must be at least 1", ValidationError{Rule: "min", Param: "1"}.Error())
+	})
+}
+
+type readUniqueTmp struct {
+	SKU       string  `excel:"SKU,unique"`
+	Warehouse string  `excel:"Warehouse,unique=location"`
+	Bin       *string `excel:"Bin,unique=location"`
+	Name      string  `excel:"Name"`
+}
+
+type readUniqueZeroTmp struct {
+	Code int `excel:"Code,unique"`
+}
+
+func TestReadUnique(t *testing.T) {
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+
+	data := [][]string{
+		{"SKU", "Warehouse", "Bin", "Name"},
+		{"A1", "North", "1", "Apple"},
+		{"A2", "North", "2", "Pear"},
+		{"A1", "South", "1", "Apple again"},
+		{"A3", "North", "2", "Plum"},
+		{"", "", "", "Blank keys"},
+		{"", "", "", "Blank keys again"},
+	}
+	if err := WriteExcel(testFile, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+
+	_, err := ReadFile[*readUniqueTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 3, ColumnIndex: 0, ColumnHeader: "SKU", Err: DuplicateError{FirstRowIndex: 1, Headers: []string{"SKU"}}},
+			{RowIndex: 4, ColumnIndex: 1, ColumnHeader: "Warehouse", Err: DuplicateError{FirstRowIndex: 2, Headers: []string{"Warehouse", "Bin"}}},
+		},
+	}, err)
+	equal(t, "error unmarshalling column \"Warehouse\" in row 5: duplicate value of Warehouse, Bin, first used in row 3",
+		err.(ContentError).FieldErrors[1].Error())
+
+	models, err := ReadFile[*readUniqueTmp](testFile, WithFilter(func(r *readUniqueTmp) bool { return r.Name != "Apple again" && r.Name != "Plum" }))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 4, len(models))
+
+	// Duplicates are handled like unmarshal errors
+	models, err = ReadFile[*readUniqueTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorIgnore))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 6, len(models))
+	var handled []string
+	models, err = ReadFile[*readUniqueTmp](testFile, WithReadConfig(func(rc *ReadConfig) {
+		rc.RowUnmarshalErrorHandler = func(cell *Cell, _ *reflect.Value, fi FieldInfo) {
+			handled = append(handled, fi.Header+"="+cell.Value)
+		}
+	}))
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 6, len(models))
+	equal(t, []string{"SKU=A1", "Warehouse=North"}, handled)
+
+	// Zero values are no empty cells
+	if err = WriteExcel(testFile, [][]string{{"Code"}, {"0"}, {"1"}, {"0"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	_, err = ReadFile[*readUniqueZeroTmp](testFile, WithUnmarshalErrorHandling(UnmarshalErrorCollect))
+	equal(t, ContentError{
+		FieldErrors: []FieldError{
+			{RowIndex: 3, ColumnIndex: 0, ColumnHeader: "Code", Err: DuplicateError{FirstRowIndex: 1, Headers: []string{"Code"}}},
+		},
+	}, err)
+}
@@ -16,10 +16,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
-
-	"codeberg.org/tealeg/xlsx/v4"
 )
 
 var ErrNegativeUInt = errors.New("negative integer provided for unsigned field")
@@ -30,6 +29,10 @@ var ErrNoRecognizedFormat = errors.New("no recognized format")
 // but casting to it at runtime failed for some reason.
 var ErrCannotCastUnmarshaler = errors.New("cannot cast to unmarshaler interface")
 
+// ErrCannotCastMarshaler is returned in case a field technically implements a marshaler interface,
+// but casting to it at runtime failed for some reason.
+var ErrCannotCastMarshaler = errors.New("cannot cast to marshaler interface")
+
 var DefaultUnmarshalFuncs = map[reflect.Kind]UnmarshalExcelFunc{
 	reflect.String:  UnmarshalString,
 	reflect.Bool:    UnmarshalBool,
@@ -48,6 +51,24 @@ var DefaultUnmarshalFuncs = map[reflect.Kind]UnmarshalExcelFunc{
 	reflect.Float64: UnmarshalFloat,
 }
 
+var DefaultMarshalFuncs = map[reflect.Kind]MarshalExcelFunc{
+	reflect.String:  MarshalString,
+	reflect.Bool:    MarshalBool,
+	reflect.Int:     MarshalInt,
+	reflect.Int8:    MarshalInt,
+	reflect.Int16:   MarshalInt,
+	reflect.Int32:   MarshalInt,
+	reflect.Int64:   MarshalInt,
+	reflect.Uint:    MarshalUInt,
+	reflect.Uintptr: MarshalUInt,
+	reflect.Uint8:   MarshalUInt,
+	reflect.Uint16:  MarshalUInt,
+	reflect.Uint32:  MarshalUInt,
+	reflect.Uint64:  MarshalUInt,
+	reflect.Float32: MarshalFloat,
+	reflect.Float64: MarshalFloat,
+}
+
 type ExcelUnmarshalParameters struct {
 	// See ReadConfig.TrimSpace
 	TrimSpace bool
@@ -58,12 +79,18 @@ type ExcelUnmarshalParameters struct {
 }
 
 type ExcelUnmarshaler interface {
-	UnmarshalExcel(cell *xlsx.Cell, params *ExcelUnmarshalParameters) error
+	UnmarshalExcel(cell *Cell, params *ExcelUnmarshalParameters) error
 }
 
-type UnmarshalExcelFunc func(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error
+type UnmarshalExcelFunc func(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error
 
-func UnmarshalString(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+type ExcelMarshaler interface {
+	MarshalExcel(cell *Cell) error
+}
+
+type MarshalExcelFunc func(srcValue reflect.Value, cell *Cell) error
+
+func UnmarshalString(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	str, err := cell.FormattedValue()
 	if err != nil {
 		return fmt.Errorf("error formatting string value: %w", err)
@@ -75,12 +102,12 @@ func UnmarshalString(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnma
 	return nil
 }
 
-func UnmarshalBool(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalBool(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	destValue.SetBool(cell.Bool())
 	return nil
 }
 
-func UnmarshalInt(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalInt(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	val, err := cell.Int64()
 	if err != nil {
 		return fmt.Errorf("error parsing cell as integer value: %w", err)
@@ -92,7 +119,7 @@ func UnmarshalInt(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarsh
 	return nil
 }
 
-func UnmarshalUInt(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalUInt(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	val, err := cell.Int64()
 	if err != nil {
 		return fmt.Errorf("error parsing cell as integer value: %w", err)
@@ -108,7 +135,7 @@ func UnmarshalUInt(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmars
 	return nil
 }
 
-func UnmarshalFloat(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalFloat(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	val, err := cell.Float()
 	if err != nil {
 		return fmt.Errorf("error parsing cell as float value: %w", err)
@@ -120,7 +147,7 @@ func UnmarshalFloat(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmar
 	return nil
 }
 
-func UnmarshalTime(destValue reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalTime(destValue reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	var val time.Time
 	if cell.IsTime() {
 		var err error
@@ -169,7 +196,7 @@ func getFieldInterface(destField reflect.Value) any {
 	return destFieldPointer.Interface()
 }
 
-func UnmarshalExcelUnmarshaler(destField reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalExcelUnmarshaler(destField reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	unmarshaler, ok := getFieldInterface(destField).(ExcelUnmarshaler)
 	if !ok {
 		// This should not happen at runtime,
@@ -180,7 +207,7 @@ func UnmarshalExcelUnmarshaler(destField reflect.Value, cell *xlsx.Cell, params
 	return unmarshaler.UnmarshalExcel(cell, params)
 }
 
-func UnmarshalTextUnmarshaler(destField reflect.Value, cell *xlsx.Cell, params *ExcelUnmarshalParameters) error {
+func UnmarshalTextUnmarshaler(destField reflect.Value, cell *Cell, params *ExcelUnmarshalParameters) error {
 	unmarshaler, ok := getFieldInterface(destField).(encoding.TextUnmarshaler)
 	if !ok {
 		// This should not happen at runtime,
@@ -190,3 +217,65 @@ func UnmarshalTextUnmarshaler(destField reflect.Value, cell *xlsx.Cell, params *
 
 	return unmarshaler.UnmarshalText([]byte(cell.Value))
 }
+
+func MarshalString(srcValue reflect.Value, cell *Cell) error {
+	cell.SetString(srcValue.String())
+	return nil
+}
+
+func MarshalBool(srcValue reflect.Value, cell *Cell) error {
+	cell.SetBool(srcValue.Bool())
+	return nil
+}
+
+func MarshalInt(srcValue reflect.Value, cell *Cell) error {
+	cell.SetNumeric(strconv.FormatInt(srcValue.Int(), 10))
+	return nil
+}
+
+func MarshalUInt(srcValue reflect.Value, cell *Cell) error {
+	cell.SetNumeric(strconv.FormatUint(srcValue.Uint(), 10))
+	return nil
+}
+
+func MarshalFloat(srcValue reflect.Value, cell *Cell) error {
+	// Same as xlsx.Cell.SetValue, to not get scientific notation
+	cell.SetNumeric(strconv.FormatFloat(srcValue.Float(), 'f', -1, srcValue.Type().Bits()))
+	return nil
+}
+
+func MarshalTime(srcValue reflect.Value, cell *Cell) error {
+	cell.SetDateTime(srcValue.Interface().(time.Time))
+	return nil
+}
+
+func MarshalExcelMarshaler(srcField reflect.Value, cell *Cell) error {
+	if srcField.Kind() == reflect.Pointer && srcField.IsNil() {
+		// Nothing to call the marshaler on, keep the cell empty
+		return nil
+	}
+	marshaler, ok := getFieldInterface(srcField).(ExcelMarshaler)
+	if !ok {
+		return ErrCannotCastMarshaler
+	}
+
+	return marshaler.MarshalExcel(cell)
+}
+
+func MarshalTextMarshaler(srcField reflect.Value, cell *Cell) error {
+	if srcField.Kind() == reflect.Pointer && srcField.IsNil() {
+		// Nothing to call the marshaler on, keep the cell empty
+		return nil
+	}
+	marshaler, ok := getFieldInterface(srcField).(encoding.TextMarshaler)
+	if !ok {
+		return ErrCannotCastMarshaler
+	}
+
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return err
+	}
+	cell.SetString(string(text))
+	return nil
+}
@@ -17,8 +17,6 @@ import (
 	"reflect"
 	"testing"
 	"time"
-
-	"codeberg.org/tealeg/xlsx/v4"
 )
 
 type _model struct {
@@ -40,7 +38,7 @@ type _model struct {
 func TestUnmarshalString(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("S")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("string cell", func(t *testing.T) {
 		cell.SetValue("string value")
@@ -86,7 +84,7 @@ func TestUnmarshalString(t *testing.T) {
 func TestUnmarshalBool(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("B")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("true cell", func(t *testing.T) {
 		cell.SetBool(true)
@@ -110,7 +108,7 @@ func TestUnmarshalBool(t *testing.T) {
 func TestUnmarshalInt(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("I")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("positive integer cell", func(t *testing.T) {
 		cell.SetValue(123)
@@ -160,7 +158,7 @@ func TestUnmarshalInt(t *testing.T) {
 func TestUnmarshalUInt(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("U")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("positive integer cell", func(t *testing.T) {
 		cell.SetValue(123)
@@ -209,7 +207,7 @@ func TestUnmarshalUInt(t *testing.T) {
 func TestUnmarshalFloat(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("F")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("positive float cell", func(t *testing.T) {
 		cell.SetValue(123.7)
@@ -269,7 +267,7 @@ func TestUnmarshalFloat(t *testing.T) {
 func TestUnmarshalTime(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("T")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	// NOTE: Testing with too accurate of a date may result in floating point errors.
 	testTime := time.Date(2023, time.November, 13, 14, 15, 0, 0, time.UTC)
@@ -328,7 +326,7 @@ func TestUnmarshalTime(t *testing.T) {
 func TestUnmarshalExcelUnmarshaler(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("EU")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("successful unmarshalling", func(t *testing.T) {
 		cell.SetString("unit test value")
@@ -355,7 +353,7 @@ func TestUnmarshalExcelUnmarshaler(t *testing.T) {
 func TestUnmarshalTextUnmarshaler(t *testing.T) {
 	model := &_model{}
 	destField := reflect.ValueOf(model).Elem().FieldByName("TU")
-	cell := &xlsx.Cell{}
+	cell := &Cell{}
 
 	t.Run("successful unmarshalling", func(t *testing.T) {
 		cell.SetString("unit test value")
@@ -379,6 +377,35 @@ func TestUnmarshalTextUnmarshaler(t *testing.T) {
 	})
 }
 
+func TestMarshalFuncs(t *testing.T) {
+	model := &_model{S: "str", B: true, I: -12, U: 12, F: 1e-5, F32: 1.1, T: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)}
+	value := reflect.ValueOf(model).Elem()
+	marshal := func(f MarshalExcelFunc, fieldName string) *Cell {
+		cell := &Cell{}
+		if err := f(value.FieldByName(fieldName), cell); err != nil {
+			t.Fatal(err)
+		}
+		return cell
+	}
+	equal(t, "str", marshal(MarshalString, "S").Value)
+	equal(t, true, marshal(MarshalBool, "B").Bool())
+	equal(t, "-12", marshal(MarshalInt, "I").Value)
+	equal(t, "12", marshal(MarshalUInt, "U").Value)
+	equal(t, "0.00001", marshal(MarshalFloat, "F").Value)
+	equal(t, "1.1", marshal(MarshalFloat, "F32").Value)
+	cell := marshal(MarshalTime, "T")
+	tm, err := cell.GetTime(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal(t, model.T, tm)
+
+	t.Run("catch wrong type", func(t *testing.T) {
+		equal(t, ErrCannotCastMarshaler, MarshalExcelMarshaler(value.FieldByName("S"), &Cell{}))
+		equal(t, ErrCannotCastMarshaler, MarshalTextMarshaler(value.FieldByName("S"), &Cell{}))
+	})
+}
+
 func equal(t *testing.T, expected, actual any) {
 	t.Helper()
 	if !reflect.DeepEqual(expected, actual) {
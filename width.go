@@ -0,0 +1,95 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"strings"
+
+	"codeberg.org/tealeg/xlsx/v4"
+	"golang.org/x/text/width"
+)
+
+// autoFit estimates the column widths of a sheet from the cells written into it.
+// A nil *autoFit does nothing, so callers don't have to check whether it is enabled.
+type autoFit struct {
+	maxWidth float64
+	widths   map[int]float64
+	fixed    map[int]bool
+}
+
+// newAutoFit returns an autoFit limiting widths to maxWidth, if maxWidth is positive.
+func newAutoFit(maxWidth float64) *autoFit {
+	return &autoFit{maxWidth: maxWidth, widths: make(map[int]float64), fixed: make(map[int]bool)}
+}
+
+// fix excludes the column from fitting, e.g. because it has an explicit width.
+func (a *autoFit) fix(columnIndex int) {
+	if a != nil {
+		a.fixed[columnIndex] = true
+	}
+}
+
+// add widens the column of the cell to fit its formatted value.
+func (a *autoFit) add(cell *xlsx.Cell) {
+	if a == nil {
+		return
+	}
+	text, err := cell.FormattedValue()
+	if err != nil {
+		text = cell.Value
+	}
+	columnIndex, _ := cell.GetCoordinates()
+	// Some padding, like Excel does when fitting a column
+	w := textWidth(text) + 2
+	if a.maxWidth > 0 && w > a.maxWidth {
+		w = a.maxWidth
+	}
+	if w > a.widths[columnIndex] {
+		a.widths[columnIndex] = w
+	}
+}
+
+// apply sets the widths on the sheet.
+// Columns are not narrowed, if a previous write made them wider.
+func (a *autoFit) apply(sheet *xlsx.Sheet) {
+	if a == nil {
+		return
+	}
+	for columnIndex, w := range a.widths {
+		if a.fixed[columnIndex] {
+			continue
+		}
+		if col := sheet.Col(columnIndex); col != nil && col.Width != nil && *col.Width > w {
+			continue
+		}
+		sheet.SetColWidth(columnIndex+1, columnIndex+1, w)
+	}
+}
+
+// textWidth returns the width of the widest line of the text in characters,
+// counting East Asian wide and fullwidth characters, e.g. CJK, as two.
+func textWidth(text string) float64 {
+	maxWidth := 0
+	for _, line := range strings.Split(text, "\n") {
+		lineWidth := 0
+		for _, r := range line {
+			switch width.LookupRune(r).Kind() {
+			case width.EastAsianWide, width.EastAsianFullwidth:
+				lineWidth += 2
+			default:
+				lineWidth++
+			}
+		}
+		maxWidth = max(maxWidth, lineWidth)
+	}
+	return float64(maxWidth)
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 exl Author. All Rights Reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//      http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exl
+
+import (
+	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
+)
+
+func TestTextWidth(t *testing.T) {
+	equal(t, 0.0, textWidth(""))
+	equal(t, 5.0, textWidth("apple"))
+	equal(t, 4.0, textWidth("名称"))
+	equal(t, 6.0, textWidth("ＡＢＣ"))
+	equal(t, 8.0, textWidth("ab\n编号编号\nc"))
+}
+
+func TestAutoFit(t *testing.T) {
+	f := xlsx.NewFile()
+	sheet, _ := f.AddSheet("Sheet1")
+	row := sheet.AddRow()
+	short, long, fixed := row.AddCell(), row.AddCell(), row.AddCell()
+	short.SetString("ab")
+	long.SetString("a very long text which is limited by the maximum width")
+	fixed.SetString("fixed")
+
+	fit := newAutoFit(20)
+	fit.add(short)
+	fit.add(long)
+	fit.add(fixed)
+	fit.fix(2)
+	fit.apply(sheet)
+	equal(t, 4.0, *sheet.Col(0).Width)
+	equal(t, 20.0, *sheet.Col(1).Width)
+	if col := sheet.Col(2); col != nil && col.Width != nil {
+		t.Error("test failed: fixed column was fitted")
+	}
+
+	// Columns are not narrowed
+	sheet.SetColWidth(1, 1, 30)
+	fit.apply(sheet)
+	equal(t, 30.0, *sheet.Col(0).Width)
+
+	// nil does nothing
+	var noFit *autoFit
+	noFit.add(short)
+	noFit.fix(0)
+	noFit.apply(sheet)
+}
@@ -12,8 +12,13 @@
 package exl
 
 import (
+	"context"
+	"encoding"
+	"fmt"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"codeberg.org/tealeg/xlsx/v4"
 )
@@ -33,18 +38,213 @@ type (
 		// and will also not write a header.
 		// Defaults to "false".
 		IgnoreFieldsWithoutTag bool
+		// Style of the header row, e.g. CellStyle{Bold: true, FillColor: "FFD9D9D9"}.
+		// Columns are styled with tag options, see writeColumns.
+		// Defaults to no style.
+		HeaderStyle CellStyle
+		// Fit the column widths to the header and the written cells,
+		// counting CJK characters as double width.
+		// Columns with the tag option "width" keep their width.
+		// Defaults to false.
+		AutoFitWidth bool
+		// Maximum width of auto-fitted columns, 0 for no maximum.
+		// Defaults to 60.
+		MaxAutoFitWidth float64
+		// Freeze the header row, so it stays visible when scrolling.
+		// Defaults to false.
+		FreezeHeader bool
+		// Add filter dropdowns to the header row.
+		// Not used with Table, which has its own filter.
+		// Defaults to false.
+		AutoFilter bool
+		// Write the header and the data as Excel Table.
+		// Defaults to false.
+		Table bool
+		// Name of the Table, made unique in the workbook.
+		// Defaults to "", which names the tables "Table1", "Table2", ...
+		TableName string
+		// Named style of the Table, e.g. "TableStyleLight9", "" for no style.
+		// Defaults to "TableStyleMedium2".
+		TableStyle string
+		// Add a totals row below the data rows, with the function of the tag option "total"
+		// for each column: SUM, AVERAGE, MIN, MAX or COUNT.
+		// Numeric columns without the option are summed, "total=-" leaves a column empty.
+		// The totals row is not part of the Table or the AutoFilter.
+		// Defaults to false.
+		TotalsRow bool
+		// Label of the totals row, written to the first column if it has no total.
+		// Defaults to "Total".
+		TotalsLabel string
+		// The tag name for the description of a column in import templates,
+		// see WriteImportTemplate.
+		// Defaults to "desc".
+		DescriptionTagName string
+		// The tag name for the example values of a column in import templates,
+		// separated by "|", see WriteImportTemplate.
+		// Defaults to "example".
+		ExampleTagName string
+		// Called after every written row, with the number of rows written so far
+		// and the total number of rows to write, -1 if unknown, see WriteStream.
+		// Defaults to nil.
+		Progress ProgressFunc
+		// Delimiter, encoding and byte order mark of CSV files, see WriteCSV.
+		CSV CSVConfig
+	}
+	// writeColumn is a column written from a field of the row type.
+	writeColumn struct {
+		index  []int
+		header string
+		width  float64
+		style  CellStyle
+		enum   []string
+		list   string
+		// Formula with the placeholder "{row}" for the row number, see prepareFormulas
+		formula string
+		total   string
+		numeric bool
 	}
 )
 
 var defaultWriteConfig = func() *WriteConfig {
-	return &WriteConfig{SheetName: "Sheet1", TagName: "excel", IgnoreFieldsWithoutTag: false}
+	return &WriteConfig{SheetName: "Sheet1", TagName: "excel", IgnoreFieldsWithoutTag: false, MaxAutoFitWidth: 60, TableStyle: "TableStyleMedium2",
+		TotalsLabel: "Total", DescriptionTagName: "desc", ExampleTagName: "example"}
+}
+
+// writeColumns returns the columns written for the exported fields of the struct type.
+// The header is the name in the tag, or the field name; "-" skips a field.
+// Tag options style the column:
+//
+//	format=#,##0.00  number format of the cells
+//	width=14         column width
+//	align=right      horizontal alignment, left, center or right
+//	wrap             wrap text
+//	enum=A|B         dropdown of the allowed values
+//	list=Lists       hidden lookup sheet holding the values of the dropdown
+//	formula=F        formula of the cells instead of the field value, e.g. "{Price}*{Qty}" or "C{row}*D{row}"
+//	total=AVERAGE    function of the totals row, see WriteConfig.TotalsRow
+func writeColumns(typ reflect.Type, wc *WriteConfig) ([]writeColumn, error) {
+	columns := make([]writeColumn, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, tagged := sf.Tag.Lookup(wc.TagName)
+		if (!tagged && wc.IgnoreFieldsWithoutTag) || tag == "-" {
+			continue
+		}
+		column, err := tagColumn(parseTag(tag), sf.Index, sf.Name, sf.Type)
+		if err != nil {
+			return nil, err
+		}
+		if !tagged || column.header == "" {
+			column.header = sf.Name
+		}
+		columns = append(columns, column)
+	}
+	if err := prepareFormulas(columns); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// tagColumn returns the column of the field with the parsed tag, see writeColumns.
+// name is the name of the field in errors.
+func tagColumn(ft fieldTag, index []int, name string, typ reflect.Type) (writeColumn, error) {
+	column := writeColumn{
+		index:   index,
+		header:  ft.Name,
+		style:   CellStyle{Format: ft.Options["format"], Align: ft.Options["align"], Wrap: ft.Has("wrap")},
+		enum:    tagEnum(ft),
+		list:    ft.Options["list"],
+		formula: strings.TrimPrefix(ft.Options["formula"], "="),
+		total:   strings.ToUpper(ft.Options["total"]),
+		numeric: numericKind(typ),
+	}
+	if width, ok := ft.Options["width"]; ok {
+		var err error
+		if column.width, err = strconv.ParseFloat(width, 64); err != nil || column.width <= 0 {
+			return column, fmt.Errorf("%w width=%s on field %s", ErrInvalidTag, width, name)
+		}
+	}
+	switch column.style.Align {
+	case "", "left", "center", "right":
+	default:
+		return column, fmt.Errorf("%w align=%s on field %s", ErrInvalidTag, column.style.Align, name)
+	}
+	if err := checkDropdown(column, name); err != nil {
+		return column, err
+	}
+	if column.total != "" && column.total != "-" && !totalFuncs[column.total] {
+		return column, fmt.Errorf("%w total=%s on field %s", ErrInvalidTag, column.total, name)
+	}
+	return column, nil
+}
+
+// writeHeader adds the header row and sets the column widths.
+func writeHeader(sheet *xlsx.Sheet, columns []writeColumn, wc *WriteConfig, styles styleCache, fit *autoFit) {
+	r := sheet.AddRow()
+	for i, column := range columns {
+		cell := r.AddCell()
+		cell.SetString(column.header)
+		styles.apply(cell, wc.HeaderStyle)
+		fit.add(cell)
+		if column.width > 0 {
+			sheet.SetColWidth(i+1, i+1, column.width)
+			fit.fix(i)
+		}
+	}
 }
 
-func write(sheet *xlsx.Sheet, data []any) {
+// writeRow adds a row with the fields of the struct value.
+func writeRow(sheet *xlsx.Sheet, columns []writeColumn, value reflect.Value, styles styleCache, fit *autoFit, totals *columnTotals) error {
 	r := sheet.AddRow()
-	for _, cell := range data {
-		r.AddCell().SetValue(cell)
+	cells, err := marshalRow(columns, r.GetCoordinate(), value)
+	if err != nil {
+		return err
+	}
+	for i, cell := range cells {
+		x := r.AddCell()
+		cell.copyTo(x)
+		styles.apply(x, columns[i].style)
+		fit.add(x)
+		totals.add(i, cell)
 	}
+	return nil
+}
+
+// marshalRow marshals the columns of the struct value in the 0-based row.
+func marshalRow(columns []writeColumn, rowIndex int, value reflect.Value) ([]*Cell, error) {
+	cells := make([]*Cell, 0, len(columns))
+	for _, column := range columns {
+		cell := &Cell{}
+		if err := marshalColumn(cell, column, rowIndex, value, cells); err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+	return cells, nil
+}
+
+// marshalColumn writes the field of the column, or its formula, into the cell of the 0-based row.
+// cells are the cells of the row written before, for the cached values of formulas.
+func marshalColumn(cell *Cell, column writeColumn, rowIndex int, value reflect.Value, cells []*Cell) error {
+	field := value.FieldByIndex(column.index)
+	var err error
+	if column.formula != "" {
+		err = setFormula(cell, column, rowIndex, field, func(columnIndex int) (float64, bool) {
+			if columnIndex >= len(cells) {
+				return 0, false
+			}
+			return numericValue(cells[columnIndex])
+		})
+	} else {
+		err = marshalCell(cell, field)
+	}
+	if err != nil {
+		return fmt.Errorf("error marshalling column \"%s\" in row %d: %w", column.header, rowIndex+1, err)
+	}
+	return nil
 }
 
 // Write defines write []T to excel file
@@ -53,9 +253,19 @@ func write(sheet *xlsx.Sheet, data []any) {
 //
 // params: typed parameter T, must be implements exl.Bind
 func Write[T WriteConfigurator](file string, ts []T) error {
+	return WriteContext(context.Background(), file, ts)
+}
+
+// WriteContext is Write with a context, which is checked between rows.
+// Writing ends with the error of the context once it is done,
+// no file is written then.
+func WriteContext[T WriteConfigurator](ctx context.Context, file string, ts []T) error {
 	f := xlsx.NewFile()
-	write0(f, ts)
-	return f.Save(file)
+	tables, err := write0(ctx, f, ts)
+	if err != nil {
+		return err
+	}
+	return saveFile(f, tables, file)
 }
 
 // WriteTo defines write to []T to excel file
@@ -64,12 +274,21 @@ func Write[T WriteConfigurator](file string, ts []T) error {
 //
 // params: typed parameter T, must be implements exl.Bind
 func WriteTo[T WriteConfigurator](w io.Writer, ts []T) error {
+	return WriteToContext(context.Background(), w, ts)
+}
+
+// WriteToContext is WriteTo with a context, see WriteContext.
+// Nothing is written to w if the context is done before all rows are written.
+func WriteToContext[T WriteConfigurator](ctx context.Context, w io.Writer, ts []T) error {
 	f := xlsx.NewFile()
-	write0(f, ts)
-	return f.Write(w)
+	tables, err := write0(ctx, f, ts)
+	if err != nil {
+		return err
+	}
+	return writeFile(f, tables, w)
 }
 
-func write0[T WriteConfigurator](f *xlsx.File, ts []T) {
+func write0[T WriteConfigurator](ctx context.Context, f *xlsx.File, ts []T) ([]*tableSpec, error) {
 	wc := defaultWriteConfig()
 	tT := new(T)
 	// Always configure writes, even if the provided data is empty.
@@ -77,36 +296,118 @@ func write0[T WriteConfigurator](f *xlsx.File, ts []T) {
 	// compared to files with content, because the write config would not run.
 	(*tT).WriteConfigure(wc)
 	if sheet, _ := f.AddSheet(wc.SheetName); sheet != nil {
-		typ := reflect.TypeOf(tT).Elem().Elem()
-		numField := typ.NumField()
-		header := make([]any, 0, numField)
-		ignoreField := make([]bool, numField)
-		for i := 0; i < numField; i++ {
-			fe := typ.Field(i)
-			name := fe.Name
-			if tt, have := fe.Tag.Lookup(wc.TagName); have {
-				name = tt
-			} else if wc.IgnoreFieldsWithoutTag {
-				ignoreField[i] = true
-				continue
-			}
-			header = append(header, name)
+		columns, err := writeColumns(reflect.TypeOf(tT).Elem().Elem(), wc)
+		if err != nil {
+			return nil, err
+		}
+		styles := styleCache{}
+		var fit *autoFit
+		if wc.AutoFitWidth {
+			fit = newAutoFit(wc.MaxAutoFitWidth)
 		}
 		// write header
-		write(sheet, header)
-		if len(ts) > 0 {
-			// write data
-			for _, t := range ts {
-				data := make([]any, 0, numField)
-				for i := 0; i < numField; i++ {
-					if !ignoreField[i] {
-						data = append(data, reflect.ValueOf(t).Elem().Field(i).Interface())
-					}
+		writeHeader(sheet, columns, wc, styles, fit)
+		// write data
+		totals := newColumnTotals(columns, wc)
+		for i, t := range ts {
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err = writeRow(sheet, columns, reflect.ValueOf(t).Elem(), styles, fit, totals); err != nil {
+				return nil, err
+			}
+			if wc.Progress != nil {
+				wc.Progress(i+1, len(ts))
+			}
+		}
+		table := layoutSheet(f, sheet, wc, 0)
+		totals.write(sheet, 1, styles, fit)
+		fit.apply(sheet)
+		if err = addDropdowns(f, sheet, columns, 0); err != nil {
+			return nil, err
+		}
+		if table != nil {
+			return []*tableSpec{table}, nil
+		}
+	}
+	return nil, nil
+}
+
+func GetMarshalFunc(srcField reflect.Value) MarshalExcelFunc {
+	if srcField.CanInterface() {
+
+		inf := getFieldInterface(srcField)
+		if inf != nil {
+
+			// Prefer ExcelMarshaler, if implemented
+			if _, ok := inf.(ExcelMarshaler); ok {
+				return MarshalExcelMarshaler
+			}
+
+			// Then handle specific types with special implementation
+			if srcField.Type() == timeType {
+				return MarshalTime
+			}
+			if srcField.Type() == reflect.PointerTo(timeType) {
+				return func(srcValue reflect.Value, cell *Cell) error {
+					return marshalPointer(srcValue, cell, MarshalTime)
 				}
-				write(sheet, data)
 			}
+
+			// Then utilize TextMarshaler, e.g. for things like decimal.Decimal
+			if _, ok := inf.(encoding.TextMarshaler); ok {
+				return MarshalTextMarshaler
+			}
+
 		}
 	}
+
+	// And for primitive types, use custom marshalling func
+	kind := srcField.Type().Kind()
+	isPointer := false
+	if kind == reflect.Ptr {
+		kind = srcField.Type().Elem().Kind()
+		isPointer = true
+	}
+	marshalFunc, ok := DefaultMarshalFuncs[kind]
+	if ok {
+		if isPointer {
+			return func(srcValue reflect.Value, cell *Cell) error {
+				return marshalPointer(srcValue, cell, marshalFunc)
+			}
+		}
+		return marshalFunc
+	}
+
+	return nil
+}
+
+func marshalPointer(srcPointer reflect.Value, cell *Cell, marshalFunc MarshalExcelFunc) error {
+	// Nil pointers are written as empty cells
+	if srcPointer.IsNil() {
+		return nil
+	}
+	return marshalFunc(srcPointer.Elem(), cell)
+}
+
+// marshalCell writes the value into the cell,
+// using Cell.SetValue for types without marshal func.
+func marshalCell(cell *Cell, value reflect.Value) error {
+	if marshalFunc := GetMarshalFunc(value); marshalFunc != nil {
+		return marshalFunc(value, cell)
+	}
+	cell.SetValue(value.Interface())
+	return nil
+}
+
+// marshalXLSXCell writes the value into the xlsx.Cell, see marshalCell.
+func marshalXLSXCell(x *xlsx.Cell, value reflect.Value) error {
+	cell := &Cell{NumFmt: x.NumFmt}
+	if err := marshalCell(cell, value); err != nil {
+		return err
+	}
+	cell.copyTo(x)
+	return nil
 }
 
 // WriteExcel defines write [][]string to excel
@@ -12,10 +12,19 @@
 package exl
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
+
+	"codeberg.org/tealeg/xlsx/v4"
 )
 
 type writeTmp struct {
@@ -289,3 +298,214 @@ func TestWriteWithoutRowsConfiguresHeader(t *testing.T) {
 		t.Error("test failed, expected message for second column, got: " + err.Error())
 	}
 }
+
+type writeStatus int
+
+func (s *writeStatus) MarshalExcel(cell *Cell) error {
+	switch *s {
+	case 1:
+		cell.SetString("active")
+	case 2:
+		cell.SetString("blocked")
+	default:
+		return errors.New("unknown status")
+	}
+	return nil
+}
+
+type writeCode [2]byte
+
+func (c writeCode) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%c-%c", c[0], c[1])), nil
+}
+
+type writeMarshalTmp struct {
+	Status   writeStatus `excel:"Status"`
+	Code     writeCode   `excel:"Code"`
+	CodePtr  *writeCode  `excel:"CodePtr"`
+	Level    int8        `excel:"Level"`
+	LevelPtr *int8       `excel:"LevelPtr"`
+	Ratio    float32     `excel:"Ratio"`
+	Date     time.Time   `excel:"Date"`
+	DatePtr  *time.Time  `excel:"DatePtr"`
+}
+
+func (*writeMarshalTmp) WriteConfigure(_ *WriteConfig) {}
+
+func TestGetMarshalFunc(t *testing.T) {
+	model := &writeMarshalTmp{}
+	value := reflect.ValueOf(model).Elem()
+	funcName := func(fieldName string) string {
+		f := GetMarshalFunc(value.FieldByName(fieldName))
+		if f == nil {
+			return ""
+		}
+		return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	}
+	equal(t, "github.com/go-the-way/exl.MarshalExcelMarshaler", funcName("Status"))
+	equal(t, "github.com/go-the-way/exl.MarshalTextMarshaler", funcName("Code"))
+	equal(t, "github.com/go-the-way/exl.MarshalTextMarshaler", funcName("CodePtr"))
+	equal(t, "github.com/go-the-way/exl.MarshalInt", funcName("Level"))
+	equal(t, "github.com/go-the-way/exl.MarshalFloat", funcName("Ratio"))
+	equal(t, "github.com/go-the-way/exl.MarshalTime", funcName("Date"))
+	if GetMarshalFunc(value.FieldByName("LevelPtr")) == nil || GetMarshalFunc(value.FieldByName("DatePtr")) == nil {
+		t.Error("test failed: expected marshal func for pointer")
+	}
+	if GetMarshalFunc(reflect.ValueOf([]int{})) != nil {
+		t.Error("test failed: expected no marshal func for slice")
+	}
+}
+
+func TestWriteMarshalers(t *testing.T) {
+	level := int8(-3)
+	date := time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC)
+	data := []*writeMarshalTmp{
+		{Status: 1, Code: writeCode{'A', 'B'}, CodePtr: &writeCode{'C', 'D'}, Level: 7, LevelPtr: &level, Ratio: 0.25, Date: date, DatePtr: &date},
+		{Status: 2, Code: writeCode{'E', 'F'}},
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteTo(buf, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheets[0]
+	rowValues := func(row int) []string {
+		values := make([]string, 0, 8)
+		for col := 0; col < 8; col++ {
+			cell, _ := sheet.Cell(row, col)
+			if col >= 6 && cell.Value != "" {
+				tm, _ := cell.GetTime(false)
+				values = append(values, tm.Format(time.DateOnly))
+				continue
+			}
+			values = append(values, cell.Value)
+		}
+		return values
+	}
+	equal(t, []string{"active", "A-B", "C-D", "7", "-3", "0.25", "2022-05-06", "2022-05-06"}, rowValues(1))
+	equal(t, []string{"blocked", "E-F", "", "0", "", "0", "0001-01-01", ""}, rowValues(2))
+
+	err = WriteTo(&bytes.Buffer{}, []*writeMarshalTmp{{Status: 3}})
+	equal(t, "error marshalling column \"Status\" in row 2: unknown status", err.Error())
+}
+
+type writeStyleTmp struct {
+	Name   string  `excel:"Name,width=20,wrap"`
+	Amount float64 `excel:"Amount,format=#,##0.00,width=14,align=right"`
+	Note   string
+	hidden string
+	Skip   string `excel:"-"`
+}
+
+func (*writeStyleTmp) WriteConfigure(wc *WriteConfig) {
+	wc.HeaderStyle = CellStyle{Bold: true, FillColor: "FFD9D9D9"}
+}
+
+type writeInvalidStyleTmp struct {
+	Amount float64 `excel:"Amount,align=middle"`
+}
+
+func (*writeInvalidStyleTmp) WriteConfigure(_ *WriteConfig) {}
+
+func TestWriteColumnStyles(t *testing.T) {
+	buf := &bytes.Buffer{}
+	data := []*writeStyleTmp{{"apple", 1234.5, "n1", "", ""}, {"pear", 0.25, "n2", "", ""}}
+	if err := WriteTo(buf, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheets[0]
+	equal(t, 3, sheet.MaxCol)
+	cell := func(row, col int) *xlsx.Cell {
+		c, _ := sheet.Cell(row, col)
+		return c
+	}
+	equal(t, []string{"Name", "Amount", "Note"}, []string{cell(0, 0).Value, cell(0, 1).Value, cell(0, 2).Value})
+	equal(t, true, cell(0, 0).GetStyle().Font.Bold)
+	equal(t, "FFD9D9D9", cell(0, 2).GetStyle().Fill.FgColor)
+
+	equal(t, "#,##0.00", cell(1, 1).NumFmt)
+	equal(t, "1234.5", cell(1, 1).Value)
+	equal(t, "right", cell(2, 1).GetStyle().Alignment.Horizontal)
+	equal(t, true, cell(2, 0).GetStyle().Alignment.WrapText)
+	equal(t, false, cell(2, 2).GetStyle().Font.Bold)
+
+	equal(t, 20.0, *sheet.Col(0).Width)
+	equal(t, 14.0, *sheet.Col(1).Width)
+
+	err = WriteTo(&bytes.Buffer{}, []*writeInvalidStyleTmp{})
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Error("test failed: expected ErrInvalidTag")
+	}
+}
+
+type writeAutoFitTmp struct {
+	ID   int    `excel:"ID"`
+	Name string `excel:"名称"`
+	Note string `excel:"Note,width=8"`
+}
+
+func (*writeAutoFitTmp) WriteConfigure(wc *WriteConfig) {
+	wc.AutoFitWidth = true
+	wc.MaxAutoFitWidth = 12
+}
+
+func TestWriteAutoFitWidth(t *testing.T) {
+	buf := &bytes.Buffer{}
+	data := []*writeAutoFitTmp{{1, "苹果", "n"}, {1000, "a long name of a pear", "a long note"}}
+	if err := WriteTo(buf, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheets[0]
+	equal(t, 6.0, *sheet.Col(0).Width)
+	equal(t, 12.0, *sheet.Col(1).Width)
+	equal(t, 8.0, *sheet.Col(2).Width)
+}
+
+type writeProgressTmp struct {
+	Name1 string `excel:"Name1"`
+}
+
+var writeProgress [][2]int
+
+func (*writeProgressTmp) WriteConfigure(wc *WriteConfig) {
+	wc.Progress = func(processed, total int) {
+		writeProgress = append(writeProgress, [2]int{processed, total})
+	}
+}
+
+func TestWriteContext(t *testing.T) {
+	data := []*writeProgressTmp{{"a"}, {"b"}}
+	writeProgress = nil
+	buf := &bytes.Buffer{}
+	if err := WriteToContext(context.Background(), buf, data); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, [][2]int{{1, 2}, {2, 2}}, writeProgress)
+	models, err := ReadBinary[*readTmp](buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 2, len(models))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	equal(t, context.Canceled, WriteToContext(ctx, buf, data))
+	equal(t, 0, buf.Len())
+	testFile := path.Join(t.TempDir(), "tmp.xlsx")
+	equal(t, context.Canceled, WriteContext(ctx, testFile, data))
+	if _, err = os.Stat(testFile); !errors.Is(err, os.ErrNotExist) {
+		t.Error("test failed: expected no file, got", err)
+	}
+}
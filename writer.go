@@ -12,6 +12,7 @@
 package exl
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -23,119 +24,216 @@ import (
 // Writer define a writer for exl
 type Writer struct {
 	file      *xlsx.File
+	wc        *WriteConfig
 	mapHeader []reflect.Value
-	ignore    map[int]struct{}
+	styles    styleCache
+	fit       *autoFit
+	totals    *columnTotals
+	tables    []*tableSpec
+}
+
+type (
+	// WriterOption configures a Writer, see NewWriter.
+	WriterOption  func(wo *writerOptions)
+	writerOptions struct {
+		fileOptions []xlsx.FileOption
+		wc          *WriteConfig
+	}
+)
+
+// WithDiskCellStore keeps the cells of the Writer on disk instead of in memory,
+// for workbooks too large for the memory. Writing is slower.
+func WithDiskCellStore() WriterOption {
+	return func(wo *writerOptions) { wo.fileOptions = append(wo.fileOptions, xlsx.UseDiskVCellStore) }
+}
+
+// WithWriteConfig applies a configuration func to the WriteConfig of the Writer,
+// like Writer.Configure.
+func WithWriteConfig(configure func(wc *WriteConfig)) WriterOption {
+	return func(wo *writerOptions) { configure(wo.wc) }
 }
 
 // NewWriter returns new exl writer
-func NewWriter(options ...xlsx.FileOption) *Writer {
-	w := &Writer{file: xlsx.NewFile(options...)}
+func NewWriter(options ...WriterOption) *Writer {
+	wo := &writerOptions{wc: defaultWriteConfig()}
+	for _, option := range options {
+		option(wo)
+	}
+	w := &Writer{file: xlsx.NewFile(wo.fileOptions...), wc: wo.wc, styles: styleCache{}}
 	w.reset()
 	return w
 }
 
 // Write or append the param data into sheet
 func (w *Writer) Write(sheet string, data any) error {
+	return w.WriteContext(context.Background(), sheet, data)
+}
+
+// WriteContext is Write with a context, which is checked between rows.
+// Writing ends with the error of the context once it is done,
+// the rows written so far stay in the sheet.
+func (w *Writer) WriteContext(ctx context.Context, sheet string, data any) error {
 	if sht, ok := w.file.Sheet[sheet]; ok {
 		w.reset()
-		return w.writeSheet(sht, data)
+		return w.writeSheet(ctx, sht, data)
 	}
 	if sht, err := w.file.AddSheet(sheet); err != nil {
 		return err
 	} else {
 		w.reset()
-		return w.writeSheet(sht, data)
+		return w.writeSheet(ctx, sht, data)
 	}
 }
 
+// AutoFitWidth fits the column widths of the following writes
+// to the header and the written cells, see WriteConfig.AutoFitWidth.
+// Widths are limited to maxWidth, 0 for no maximum.
+// Struct types can enable it with WriteConfig.AutoFitWidth as well.
+func (w *Writer) AutoFitWidth(maxWidth float64) {
+	w.wc.AutoFitWidth = true
+	w.wc.MaxAutoFitWidth = maxWidth
+}
+
+// Configure changes the WriteConfig of the following writes,
+// e.g. to freeze the header row or to write Excel Tables.
+// Struct types implementing WriteConfigurator configure their writes on top of it.
+// The sheet name and the tag settings only apply to struct types.
+func (w *Writer) Configure(configure func(wc *WriteConfig)) {
+	configure(w.wc)
+}
+
 // SaveTo the buffered binary into dist file
-func (w *Writer) SaveTo(path string) (err error) { return w.file.Save(path) }
+func (w *Writer) SaveTo(path string) (err error) { return saveFile(w.file, w.tables, path) }
 
 // WriteTo the buffered binary into new writer
-func (w *Writer) WriteTo(dw io.Writer) (n int, err error) { return 0, w.file.Write(dw) }
+func (w *Writer) WriteTo(dw io.Writer) (n int, err error) { return 0, writeFile(w.file, w.tables, dw) }
 
-func (w *Writer) writeSheet(sheet *xlsx.Sheet, data any) (err error) {
+func (w *Writer) writeSheet(ctx context.Context, sheet *xlsx.Sheet, data any) (err error) {
 	value := w.deepValue(reflect.ValueOf(data))
 	vk := value.Type().Kind()
 	switch vk {
 	case reflect.Array, reflect.Slice:
-		w.writeArrayOrSlice(sheet, value)
+		headerRow := sheet.MaxRow
+		wc, err := w.writeArrayOrSlice(ctx, sheet, value)
+		if err != nil {
+			return err
+		}
+		if table := layoutSheet(w.file, sheet, wc, headerRow); table != nil {
+			w.tables = append(w.tables, table)
+		}
+		w.totals.write(sheet, headerRow+1, w.styles, w.fit)
+		w.fit.apply(sheet)
 		return nil
 	}
 	return errors.New(fmt.Sprintf("not supported type: %v", vk))
 }
 
-func (w *Writer) writeArrayOrSlice(sheet *xlsx.Sheet, value reflect.Value) {
+// writeArrayOrSlice writes the elements and returns the WriteConfig used.
+func (w *Writer) writeArrayOrSlice(ctx context.Context, sheet *xlsx.Sheet, value reflect.Value) (*WriteConfig, error) {
 	arrLen := value.Len()
+	if typ := w.deepType(value.Type().Elem()); typ.Kind() == reflect.Struct {
+		return w.writeStructs(ctx, sheet, typ, value)
+	}
 	var header *reflect.Value
 	if arrLen > 0 {
 		dv := w.deepValue(value.Index(0))
 		header = &dv
 	}
-	w.setHeaderRow(sheet.AddRow(), w.deepType(value.Type().Elem()), header)
+	if err := w.setHeaderRow(sheet.AddRow(), w.deepType(value.Type().Elem()), header); err != nil {
+		return nil, err
+	}
 	for i := 0; i < arrLen; i++ {
-		w.setDataRow(sheet.AddRow(), w.deepValue(value.Index(i)))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := w.setDataRow(sheet.AddRow(), w.deepValue(value.Index(i))); err != nil {
+			return nil, err
+		}
+		if w.wc.Progress != nil {
+			w.wc.Progress(i+1, arrLen)
+		}
 	}
+	return w.wc, nil
 }
 
-func (w *Writer) setHeaderRow(row *xlsx.Row, typ reflect.Type, value *reflect.Value) {
+func (w *Writer) setHeaderRow(row *xlsx.Row, typ reflect.Type, value *reflect.Value) error {
 	vk := typ.Kind()
 
 	if vk == reflect.Map && value != nil {
 		for _, _mk := range value.MapKeys() {
 			mk := w.deepValue(_mk)
 			w.mapHeader = append(w.mapHeader, mk)
-			w.addCell(row, mk)
-		}
-		return
-	}
-
-	if vk == reflect.Struct {
-		for i := 0; i < typ.NumField(); i++ {
-			field := typ.Field(i)
-			excelTag, _ := field.Tag.Lookup("excel")
-			if excelTag == "" {
-				row.AddCell().SetString(field.Name)
-			} else if excelTag != "-" {
-				row.AddCell().SetString(excelTag)
-			}
-			if excelTag == "-" {
-				w.ignore[i] = struct{}{}
+			if err := w.addCell(row, mk); err != nil {
+				return err
 			}
 		}
-		return
+		return nil
 	}
 
-	row.AddCell().SetString("Unnamed")
+	cell := row.AddCell()
+	cell.SetString("Unnamed")
+	w.fit.add(cell)
+	return nil
 }
 
-func (w *Writer) setDataRow(row *xlsx.Row, value reflect.Value) {
+func (w *Writer) setDataRow(row *xlsx.Row, value reflect.Value) error {
 	vk := value.Kind()
 
 	if vk == reflect.Map {
 		for _, k := range w.mapHeader {
 			v := value.MapIndex(k)
-			w.addCell(row, v)
+			if err := w.addCell(row, v); err != nil {
+				return err
+			}
 		}
-		return
+		return nil
 	}
 
-	if vk == reflect.Struct {
-		da := value.Type()
-		for i := 0; i < da.NumField(); i++ {
-			if _, ok := w.ignore[i]; !ok {
-				w.addCell(row, w.deepValue(value.Field(i)))
-			}
+	return w.addCell(row, value)
+}
+
+// writeStructs writes the struct elements with the columns of their type,
+// configured by the type if it implements WriteConfigurator.
+// The sheet name of the WriteConfig is not used.
+func (w *Writer) writeStructs(ctx context.Context, sheet *xlsx.Sheet, typ reflect.Type, value reflect.Value) (*WriteConfig, error) {
+	wc := new(WriteConfig)
+	*wc = *w.wc
+	if configurator, ok := reflect.New(typ).Interface().(WriteConfigurator); ok {
+		configurator.WriteConfigure(wc)
+	}
+	columns, err := writeColumns(typ, wc)
+	if err != nil {
+		return nil, err
+	}
+	if wc.AutoFitWidth && w.fit == nil {
+		w.fit = newAutoFit(wc.MaxAutoFitWidth)
+	}
+	if err = addDropdowns(w.file, sheet, columns, sheet.MaxRow); err != nil {
+		return nil, err
+	}
+	writeHeader(sheet, columns, wc, w.styles, w.fit)
+	w.totals = newColumnTotals(columns, wc)
+	for i := 0; i < value.Len(); i++ {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err = writeRow(sheet, columns, w.deepValue(value.Index(i)), w.styles, w.fit, w.totals); err != nil {
+			return nil, err
+		}
+		if wc.Progress != nil {
+			wc.Progress(i+1, value.Len())
 		}
-		return
 	}
-
-	w.addCell(row, value)
+	return wc, nil
 }
 
 func (w *Writer) reset() {
 	w.mapHeader = make([]reflect.Value, 0)
-	w.ignore = make(map[int]struct{}, 0)
+	w.fit = nil
+	w.totals = nil
+	if w.wc.AutoFitWidth {
+		w.fit = newAutoFit(w.wc.MaxAutoFitWidth)
+	}
 }
 
 func (w *Writer) deepType(typ reflect.Type) reflect.Type {
@@ -152,8 +250,13 @@ func (w *Writer) deepValue(value reflect.Value) reflect.Value {
 	return value
 }
 
-func (w *Writer) addCell(row *xlsx.Row, value reflect.Value) {
+func (w *Writer) addCell(row *xlsx.Row, value reflect.Value) error {
 	if value.CanInterface() {
-		row.AddCell().SetValue(value.Interface())
+		cell := row.AddCell()
+		if err := marshalXLSXCell(cell, value); err != nil {
+			return err
+		}
+		w.fit.add(cell)
 	}
+	return nil
 }
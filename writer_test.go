@@ -13,8 +13,11 @@ package exl
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"testing"
+
+	"codeberg.org/tealeg/xlsx/v4"
 )
 
 func TestWriter(t *testing.T) {
@@ -56,3 +59,124 @@ func TestWriter(t *testing.T) {
 	_ = w.SaveTo("out.xlsx")
 	_, _ = w.WriteTo(&bytes.Buffer{})
 }
+
+func TestWriterMarshalers(t *testing.T) {
+	w := NewWriter()
+	var level int8 = 5
+	err := w.Write("struct", []struct {
+		Status   writeStatus `excel:"Status"`
+		Code     writeCode   `excel:"Code"`
+		LevelPtr *int8       `excel:"LevelPtr"`
+		NilPtr   *int8       `excel:"NilPtr"`
+	}{{1, writeCode{'A', 'B'}, &level, nil}})
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if err = w.Write("codes", []writeCode{{'C', 'D'}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if err = w.Write("error", []struct{ Status writeStatus }{{3}}); err == nil || err.Error() != "error marshalling column \"Status\" in row 2: unknown status" {
+		t.Error("test failed: expected marshal error")
+	}
+	buf := &bytes.Buffer{}
+	if _, err = w.WriteTo(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	cellValue := func(sheet string, row, col int) string {
+		cell, _ := f.Sheet[sheet].Cell(row, col)
+		return cell.Value
+	}
+	equal(t, []string{"active", "A-B", "5", ""}, []string{cellValue("struct", 1, 0), cellValue("struct", 1, 1), cellValue("struct", 1, 2), cellValue("struct", 1, 3)})
+	equal(t, "C-D", cellValue("codes", 1, 0))
+}
+
+func TestWriterColumnStyles(t *testing.T) {
+	w := NewWriter()
+	if err := w.Write("styles", []*writeStyleTmp{{"apple", 1234.5, "n1", "", ""}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheet["styles"]
+	header, _ := sheet.Cell(0, 0)
+	amount, _ := sheet.Cell(1, 1)
+	equal(t, 3, sheet.MaxCol)
+	equal(t, true, header.GetStyle().Font.Bold)
+	equal(t, "#,##0.00", amount.NumFmt)
+	equal(t, 14.0, *sheet.Col(1).Width)
+}
+
+func TestWriterAutoFitWidth(t *testing.T) {
+	w := NewWriter()
+	w.AutoFitWidth(0)
+	if err := w.Write("strings", []string{"苹果苹果", "pear"}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	if err := w.Write("map", []map[string]string{{"name": "apple"}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, 10.0, *f.Sheet["strings"].Col(0).Width)
+	equal(t, 7.0, *f.Sheet["map"].Col(0).Width)
+}
+
+func TestWriterWriteContext(t *testing.T) {
+	w := NewWriter()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var progress [][2]int
+	w.Configure(func(wc *WriteConfig) {
+		wc.Progress = func(processed, total int) {
+			progress = append(progress, [2]int{processed, total})
+			if processed == 2 {
+				cancel()
+			}
+		}
+	})
+	equal(t, context.Canceled, w.WriteContext(ctx, "int", []int{1, 2, 3}))
+	equal(t, [][2]int{{1, 3}, {2, 3}}, progress)
+	// The header and the rows written before the cancellation stay
+	equal(t, 3, w.file.Sheet["int"].MaxRow)
+
+	progress = nil
+	if err := w.WriteContext(context.Background(), "struct", []struct{ ID int }{{1}, {2}}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	equal(t, [][2]int{{1, 2}, {2, 2}}, progress)
+}
+
+func TestWriterOptions(t *testing.T) {
+	w := NewWriter(WithDiskCellStore(), WithWriteConfig(func(wc *WriteConfig) { wc.FreezeHeader = true }))
+	if err := w.Write("int", []int{1, 2}); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	f, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal("test failed: " + err.Error())
+	}
+	sheet := f.Sheet["int"]
+	cell, _ := sheet.Cell(2, 0)
+	equal(t, "2", cell.Value)
+	equal(t, "frozen", sheet.SheetViews[0].Pane.State)
+}